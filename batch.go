@@ -0,0 +1,305 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"math/bits"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// shamirTable holds ∞, G, Q and G+Q in Jacobian coordinates, for computing
+// u1·G + u2·Q with one doubling per bit.
+type shamirTable [4][3]*big.Int
+
+func (c *Curve) newShamirTable(gx, gy, qx, qy *big.Int) *shamirTable {
+	var t shamirTable
+	t[0] = [3]*big.Int{new(big.Int), new(big.Int), new(big.Int)}
+	t[1] = [3]*big.Int{gx, gy, zForAffine(gx, gy)}
+	t[2] = [3]*big.Int{qx, qy, zForAffine(qx, qy)}
+	x, y, z := c.addJacobian(gx, gy, t[1][2], qx, qy, t[2][2])
+	t[3] = [3]*big.Int{x, y, z}
+	return &t
+}
+
+// shamirMult returns u1·G + u2·Q in affine coordinates.
+func (c *Curve) shamirMult(t *shamirTable, u1, u2 *big.Int) (*big.Int, *big.Int) {
+	x, y, z := new(big.Int), new(big.Int), new(big.Int)
+	n := u1.BitLen()
+	if u2.BitLen() > n {
+		n = u2.BitLen()
+	}
+	for i := n - 1; i >= 0; i-- {
+		x, y, z = c.doubleJacobian(x, y, z)
+		if e := t[u1.Bit(i)|u2.Bit(i)<<1]; e[2].Sign() != 0 {
+			x, y, z = c.addJacobian(e[0], e[1], e[2], x, y, z)
+		}
+	}
+	return c.affineFromJacobian(x, y, z)
+}
+
+// naiveMultiScalarMult returns the sum of scalars[i]·points[i] computed one
+// term at a time with ScalarMult and Add. It is the oracle the simultaneous
+// multiplications are tested against.
+func (c *Curve) naiveMultiScalarMult(points [][2]*big.Int, scalars []*big.Int) (*big.Int, *big.Int) {
+	x, y := new(big.Int), new(big.Int)
+	for i, p := range points {
+		px, py := c.ScalarMult(p[0], p[1], scalars[i])
+		x, y = c.Add(x, y, px, py)
+	}
+	return x, y
+}
+
+// batchGroupSize is the number of signatures VerifyBatchSameKey checks with
+// one random linear combination. The sign of each R is unknown, so a group
+// tries 2^(batchGroupSize-1) sign patterns.
+const batchGroupSize = 8
+
+// batchMinOrderBits is the least bit length of N at which VerifyBatchSameKey
+// combines signatures. Each sign pattern of a group matches a bad group by
+// chance with probability about 1/N, which must stay negligible.
+const batchMinOrderBits = 128
+
+// VerifyBatchSameKey verifies signatures sigs[i] = (r, s) of hashes[i], all
+// made with the public key (pubX, pubY), and returns whether all are valid
+// along with the indices of those that are not, in ascending order. The s
+// values are inverted together with BatchModInverse. Signatures are then
+// checked in groups by a random linear combination: with R_i lifted from
+// r_i and random 128-bit z_i, a group is accepted if
+//
+//	(Σ z_i·u1_i)·G + (Σ z_i·u2_i)·Q = Σ ±z_i·R_i
+//
+// for some choice of signs, since an ECDSA signature only fixes R up to
+// sign. This costs one simultaneous multiplication over a table of G, Q and
+// G+Q built once for the key, plus short multiplications of the R_i, instead
+// of a full one per signature. If a group fails, its signatures are checked
+// one by one. On curves with a cofactor or with N below 2^128, and for r
+// that could be the x-coordinate reduced mod N of more than one Point,
+// signatures are always checked one by one. Entries beyond the shorter of
+// hashes and sigs are reported as invalid.
+func (c *Curve) VerifyBatchSameKey(pubX, pubY *big.Int, hashes [][]byte, sigs [][2]*big.Int) (bool, []int) {
+	n := len(hashes)
+	if len(sigs) > n {
+		n = len(sigs)
+	}
+	var bad []int
+	if !c.IsOnCurve(pubX, pubY) {
+		for i := 0; i < n; i++ {
+			bad = append(bad, i)
+		}
+		return n == 0, bad
+	}
+
+	N := c.N
+	m := len(hashes)
+	if len(sigs) < m {
+		m = len(sigs)
+	}
+	ss := make([]*big.Int, m)
+	for i := 0; i < m; i++ {
+		r, s := sigs[i][0], sigs[i][1]
+		if r == nil || s == nil || r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(N) >= 0 || s.Cmp(N) >= 0 {
+			continue
+		}
+		ss[i] = s
+	}
+	inv := make([]*big.Int, m)
+	var idx []int
+	var valid []*big.Int
+	for i, s := range ss {
+		if s != nil {
+			idx = append(idx, i)
+			valid = append(valid, s)
+		}
+	}
+	for j, w := range BatchModInverse(valid, N) {
+		inv[idx[j]] = w
+	}
+
+	t := c.newShamirTable(c.Gx, c.Gy, pubX, pubY)
+	verifyOne := func(e batchEntry) {
+		x, y := c.shamirMult(t, e.u1, e.u2)
+		if IsInfinity(x, y) || x.Mod(x, N).Cmp(sigs[e.i][0]) != 0 {
+			bad = append(bad, e.i)
+		}
+	}
+
+	// r + N < P leaves more than one candidate x-coordinate for R
+	rMax := new(big.Int).Sub(c.P, N)
+	batchable := N.BitLen() >= batchMinOrderBits && c.H != nil && c.H.Cmp(big.NewInt(1)) == 0
+	var group []batchEntry
+	flush := func() {
+		if len(group) > 0 && !c.verifyBatchGroup(t, group) {
+			for _, e := range group {
+				verifyOne(e)
+			}
+		}
+		group = group[:0]
+	}
+	for i := 0; i < m; i++ {
+		if inv[i] == nil {
+			bad = append(bad, i)
+			continue
+		}
+		u1 := c.hashToInt(hashes[i])
+		u1.Mul(u1, inv[i])
+		u1.Mod(u1, N)
+		u2 := new(big.Int).Mul(sigs[i][0], inv[i])
+		u2.Mod(u2, N)
+		e := batchEntry{i: i, u1: u1, u2: u2}
+
+		r := sigs[i][0]
+		if !batchable || r.Cmp(rMax) < 0 {
+			verifyOne(e)
+			continue
+		}
+		y := modSqrt(c.evaluatePolynomial(r), c.P)
+		if y == nil {
+			// r is not the x-coordinate of any Point
+			bad = append(bad, i)
+			continue
+		}
+		e.rx, e.ry = r, y
+		if group = append(group, e); len(group) == batchGroupSize {
+			flush()
+		}
+	}
+	flush()
+	for i := m; i < n; i++ {
+		bad = append(bad, i)
+	}
+
+	sort.Ints(bad)
+	return len(bad) == 0, bad
+}
+
+// batchEntry is a signature in VerifyBatchSameKey: its index, u1 and u2,
+// and a Point R = ±(u1·G + u2·Q) if it is valid.
+type batchEntry struct {
+	i              int
+	u1, u2, rx, ry *big.Int
+}
+
+// verifyBatchGroup reports whether (Σ z_i·u1_i)·G + (Σ z_i·u2_i)·Q equals
+// Σ ±z_i·R_i for random z_i and some choice of signs. The signs are walked
+// in Gray code order, so each pattern costs one addition.
+func (c *Curve) verifyBatchGroup(t *shamirTable, group []batchEntry) bool {
+	N, P := c.N, c.P
+	a, b := new(big.Int), new(big.Int)
+	bound := new(big.Int).Lsh(big.NewInt(1), 128)
+	// d[j] = 2·z_j·R_j and its negative, for flipping the sign of term j
+	d := make([][2][3]*big.Int, len(group))
+	sx, sy, sz := new(big.Int), new(big.Int), new(big.Int)
+	for j, e := range group {
+		z, err := rand.Int(c.random(), bound)
+		if err != nil {
+			return false
+		}
+		z.Add(z, big.NewInt(1))
+		a.Add(a, new(big.Int).Mul(z, e.u1))
+		b.Add(b, new(big.Int).Mul(z, e.u2))
+
+		zx, zy := c.ScalarMult(e.rx, e.ry, z)
+		if IsInfinity(zx, zy) {
+			return false
+		}
+		sx, sy, sz = c.addJacobian(zx, zy, big.NewInt(1), sx, sy, sz)
+		dx, dy := c.Double(zx, zy)
+		ndx, ndy := c.Neg(dx, dy)
+		d[j] = [2][3]*big.Int{
+			{dx, dy, zForAffine(dx, dy)},
+			{ndx, ndy, zForAffine(ndx, ndy)},
+		}
+	}
+	tx, ty := c.shamirMult(t, a.Mod(a, N), b.Mod(b, N))
+
+	// S = Σ ε_j·z_j·R_j, starting from all ε_j = +1; ε_0 stays +1 and the
+	// comparison with ±T covers ε_0 = -1
+	neg := make([]bool, len(group))
+	for k := 0; ; k++ {
+		if equalJacobianUpToSign(sx, sy, sz, tx, ty, P) {
+			return true
+		}
+		if k+1 == 1<<(len(group)-1) {
+			return false
+		}
+		// the term to flip is the lowest set bit of k+1, offset by ε_0
+		j := bits.TrailingZeros(uint(k+1)) + 1
+		step := d[j][1]
+		if neg[j] {
+			step = d[j][0]
+		}
+		neg[j] = !neg[j]
+		sx, sy, sz = c.addJacobian(step[0], step[1], step[2], sx, sy, sz)
+	}
+}
+
+// equalJacobianUpToSign reports whether the Jacobian (x, y, z) is ±(tx, ty),
+// without leaving Jacobian coordinates.
+func equalJacobianUpToSign(x, y, z, tx, ty, P *big.Int) bool {
+	if z.Sign() == 0 || IsInfinity(tx, ty) {
+		return z.Sign() == 0 && IsInfinity(tx, ty)
+	}
+	z2 := new(big.Int).Mul(z, z)
+	z2.Mod(z2, P)
+	v := new(big.Int).Mul(tx, z2)
+	if v.Sub(v, x).Mod(v, P).Sign() != 0 {
+		return false
+	}
+	z3 := z2.Mul(z2, z)
+	z3.Mod(z3, P)
+	v.Mul(ty, z3)
+	w := new(big.Int).Sub(v, y)
+	if w.Mod(w, P).Sign() == 0 {
+		return true
+	}
+	return v.Add(v, y).Mod(v, P).Sign() == 0
+}
+
+// MarshalBatch returns Marshal(points[i][0], points[i][1]) for every i,
+// spreading the work over at most GOMAXPROCS goroutines.
+func (c *Curve) MarshalBatch(points [][2]*big.Int) [][]byte {
+	out := make([][]byte, len(points))
+	parallelFor(len(points), func(i int) {
+		out[i] = c.Marshal(points[i][0], points[i][1])
+	})
+	return out
+}
+
+// UnmarshalBatch decodes every element of data as Unmarshal does, spreading
+// the work over at most GOMAXPROCS goroutines. Points and errors are
+// returned by index; an element that fails leaves a nil pair and its error,
+// ErrPointEncoding if it is not in uncompressed form and ErrNotOnCurve if
+// the Point is not on the curve, and doesn't affect the others.
+func (c *Curve) UnmarshalBatch(data [][]byte) ([][2]*big.Int, []error) {
+	points := make([][2]*big.Int, len(data))
+	errs := make([]error, len(data))
+	parallelFor(len(data), func(i int) {
+		x, y, err := c.unmarshal(data[i])
+		points[i], errs[i] = [2]*big.Int{x, y}, err
+	})
+	return points, errs
+}
+
+// parallelFor calls f(i) for 0 <= i < n from a pool of at most GOMAXPROCS
+// goroutines and returns when all calls have.
+func parallelFor(n int, f func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	var next int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(atomic.AddInt64(&next, 1) - 1); i < n; i = int(atomic.AddInt64(&next, 1) - 1) {
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,230 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestVerifyBatchSameKey(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, x, y, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var hashes [][]byte
+		var sigs [][2]*big.Int
+		for i := 0; i < 10; i++ {
+			h := sha256.Sum256([]byte(fmt.Sprint("message ", i)))
+			r, s := curve.Sign(priv, h[:])
+			hashes = append(hashes, h[:])
+			sigs = append(sigs, [2]*big.Int{r, s})
+		}
+		if ok, bad := curve.VerifyBatchSameKey(x, y, hashes, sigs); !ok || len(bad) != 0 {
+			t.Errorf("all valid: got: %v, %v", ok, bad)
+		}
+
+		// signature 3 is moved to another message, 7 has s out of range; on
+		// the small curves, 3 can still verify by chance
+		h := sha256.Sum256([]byte("forged"))
+		hashes[3] = h[:]
+		sigs[7] = [2]*big.Int{sigs[7][0], curve.N}
+		want := []int{7}
+		if !curve.Verify(x, y, hashes[3], sigs[3][0], sigs[3][1]) {
+			want = []int{3, 7}
+		}
+		if ok, bad := curve.VerifyBatchSameKey(x, y, hashes, sigs); ok || !reflect.DeepEqual(bad, want) {
+			t.Errorf("forgeries: got: %v, %v, want: false, %v", ok, bad, want)
+		}
+
+		for i := range hashes {
+			want := curve.Verify(x, y, hashes[i], sigs[i][0], sigs[i][1])
+			_, bad := curve.VerifyBatchSameKey(x, y, hashes[i:i+1], sigs[i:i+1])
+			if got := len(bad) == 0; got != want {
+				t.Errorf("signature %d: got: %v, want: %v", i, got, want)
+			}
+		}
+
+		if ok, bad := curve.VerifyBatchSameKey(x, y, hashes[:2], sigs[:3]); ok || !reflect.DeepEqual(bad, []int{2}) {
+			t.Errorf("length mismatch: got: %v, %v, want: false, [2]", ok, bad)
+		}
+	})
+}
+
+func TestVerifyBatchSameKeyCombined(t *testing.T) {
+	// curves on which signatures are combined, with groups of both signs of
+	// R, a partial last group and forgeries spread over several groups
+	for _, name := range []string{"S256", "P384"} {
+		curve := sampleCurves()[name]
+		priv, x, y, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hashes [][]byte
+		var sigs [][2]*big.Int
+		for i := 0; i < 2*batchGroupSize+3; i++ {
+			h := sha256.Sum256([]byte(fmt.Sprint("message ", i)))
+			r, s := curve.Sign(priv, h[:])
+			if i%3 == 0 {
+				// (r, N - s) is valid too, with -R in place of R
+				s.Sub(curve.N, s)
+			}
+			hashes = append(hashes, h[:])
+			sigs = append(sigs, [2]*big.Int{r, s})
+		}
+		if ok, bad := curve.VerifyBatchSameKey(x, y, hashes, sigs); !ok || len(bad) != 0 {
+			t.Errorf("[%s] all valid: got: %v, %v", name, ok, bad)
+		}
+
+		want := []int{1, 9, 10, 17}
+		for _, i := range want {
+			sigs[i] = [2]*big.Int{sigs[i][0], new(big.Int).Add(sigs[i][1], big.NewInt(1))}
+		}
+		if ok, bad := curve.VerifyBatchSameKey(x, y, hashes, sigs); ok || !reflect.DeepEqual(bad, want) {
+			t.Errorf("[%s] forgeries: got: %v, %v, want: false, %v", name, ok, bad, want)
+		}
+	}
+}
+
+func BenchmarkVerifyBatchSameKey(b *testing.B) {
+	curve := sampleCurves()["S256"]
+	priv, x, y, _ := curve.GenerateKey(rand.Reader)
+	var hashes [][]byte
+	var sigs [][2]*big.Int
+	for i := 0; i < 4*batchGroupSize; i++ {
+		h := sha256.Sum256([]byte(fmt.Sprint("message ", i)))
+		r, s := curve.Sign(priv, h[:])
+		hashes = append(hashes, h[:])
+		sigs = append(sigs, [2]*big.Int{r, s})
+	}
+	b.Run("Batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			curve.VerifyBatchSameKey(x, y, hashes, sigs)
+		}
+	})
+	b.Run("Verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range hashes {
+				curve.Verify(x, y, hashes[j], sigs[j][0], sigs[j][1])
+			}
+		}
+	})
+}
+
+func TestMultiScalarMultOracle(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		rnd := mrand.New(mrand.NewSource(1))
+		randScalar := func() *big.Int {
+			switch rnd.Intn(6) {
+			case 0:
+				return new(big.Int)
+			case 1:
+				return big.NewInt(1)
+			case 2:
+				return new(big.Int).Set(curve.N)
+			case 3:
+				return new(big.Int).Sub(curve.N, big.NewInt(1))
+			default:
+				return new(big.Int).Rand(rnd, curve.N)
+			}
+		}
+
+		gx, gy := curve.Gx, curve.Gy
+		ngx, ngy := curve.Neg(gx, gy)
+		pool := [][2]*big.Int{{gx, gy}, {ngx, ngy}, {new(big.Int), new(big.Int)}}
+		for i := 0; i < 3; i++ {
+			x, y := curve.ScalarBaseMult(new(big.Int).Rand(rnd, curve.N))
+			pool = append(pool, [2]*big.Int{x, y})
+		}
+		randPoint := func() [2]*big.Int { return pool[rnd.Intn(len(pool))] }
+
+		rounds := 200
+		if curve.BitSize > 64 {
+			rounds = 20
+		}
+		for i := 0; i < rounds; i++ {
+			p, q := randPoint(), randPoint()
+			u1, u2 := randScalar(), randScalar()
+			wx, wy := curve.naiveMultiScalarMult([][2]*big.Int{p, q}, []*big.Int{u1, u2})
+
+			x, y := curve.shamirMult(curve.newShamirTable(p[0], p[1], q[0], q[1]), u1, u2)
+			if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+				t.Errorf("[shamirMult] %d·(%d,%d) + %d·(%d,%d) got: (%d,%d), want: (%d,%d)",
+					u1, p[0], p[1], u2, q[0], q[1], x, y, wx, wy)
+			}
+
+			nqx, nqy := curve.Neg(q[0], q[1])
+			wx, wy = curve.naiveMultiScalarMult([][2]*big.Int{{gx, gy}, {nqx, nqy}}, []*big.Int{u1, u2})
+			x, y = curve.CombinedMultSub(q[0], q[1], u1, u2)
+			if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+				t.Errorf("[CombinedMultSub] %d·G - %d·(%d,%d) got: (%d,%d), want: (%d,%d)",
+					u1, u2, q[0], q[1], x, y, wx, wy)
+			}
+		}
+	})
+}
+
+func TestMarshalBatch(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		points := make([][2]*big.Int, 50)
+		for i := range points {
+			_, x, y, _ := curve.GenerateKey(rand.Reader)
+			points[i] = [2]*big.Int{x, y}
+		}
+		data := curve.MarshalBatch(points)
+		if len(data) != len(points) {
+			t.Fatalf("got: %d, want: %d", len(data), len(points))
+		}
+		for i, p := range points {
+			if want := curve.Marshal(p[0], p[1]); !reflect.DeepEqual(data[i], want) {
+				t.Errorf("[%d] got: %x, want: %x", i, data[i], want)
+			}
+		}
+
+		// invalid entries at 1, 3 and 5
+		x, y := points[0][0], points[0][1]
+		// offY stays in [0, P) so only the curve check can fail
+		offY := new(big.Int).Add(y, big.NewInt(1))
+		for offY.Mod(offY, curve.P); curve.IsOnCurve(x, offY); offY.Mod(offY, curve.P) {
+			offY.Add(offY, big.NewInt(1))
+		}
+		bad := map[int][]byte{
+			1: data[1][:len(data[1])-1],
+			3: append([]byte{2}, data[3][1:]...),
+			5: curve.Marshal(x, offY),
+		}
+		wantErr := map[int]error{1: ErrPointEncoding, 3: ErrPointEncoding, 5: ErrNotOnCurve}
+		for i, b := range bad {
+			data[i] = b
+		}
+
+		got, errs := curve.UnmarshalBatch(data)
+		if len(got) != len(data) || len(errs) != len(data) {
+			t.Fatalf("got: %d points, %d errors, want: %d", len(got), len(errs), len(data))
+		}
+		for i, b := range data {
+			wx, wy := curve.Unmarshal(b)
+			if errs[i] != wantErr[i] {
+				t.Errorf("[%d] got: %v, want: %v", i, errs[i], wantErr[i])
+			}
+			if wx == nil {
+				if got[i][0] != nil || got[i][1] != nil {
+					t.Errorf("[%d] got: (%d,%d), want: nil", i, got[i][0], got[i][1])
+				}
+				continue
+			}
+			if got[i][0].Cmp(wx) != 0 || got[i][1].Cmp(wy) != 0 {
+				t.Errorf("[%d] got: (%d,%d), want: (%d,%d)", i, got[i][0], got[i][1], wx, wy)
+			}
+		}
+
+		if got, errs := curve.UnmarshalBatch(nil); len(got) != 0 || len(errs) != 0 {
+			t.Errorf("got: %d points, %d errors, want: 0", len(got), len(errs))
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package ecc
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrChainCode       = errors.New("ecc: chain code must be 32 bytes")
+	ErrHardenedPublic  = errors.New("ecc: hardened child of a public key")
+	ErrInvalidChildKey = errors.New("ecc: invalid child key, use the next index")
+)
+
+// HardenedIndex is the first index of hardened BIP-32 child keys.
+const HardenedIndex uint32 = 1 << 31
+
+// bip32HMAC returns I = HMAC-SHA512(chainCode, data || ser32(index)) split
+// into IL as a scalar and IR.
+func bip32HMAC(chainCode, data []byte, index uint32) (*big.Int, []byte) {
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	binary.Write(mac, binary.BigEndian, index)
+	I := mac.Sum(nil)
+	return new(big.Int).SetBytes(I[:32]), I[32:]
+}
+
+// CKDpriv derives the child private key of parentPriv, serialized as by
+// MarshalPrivateKey, at index, as in BIP-32. Indices from HardenedIndex on
+// give hardened keys. It returns ErrInvalidChildKey in the rare case that
+// the index yields no valid key, and the caller should move on to the next
+// index. BIP-32 is defined for secp256k1.
+func (c *Curve) CKDpriv(parentPriv, chainCode []byte, index uint32) (childPriv, childChainCode []byte, err error) {
+	kpar, err := c.UnmarshalPrivateKey(parentPriv)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(chainCode) != 32 {
+		return nil, nil, ErrChainCode
+	}
+
+	var data []byte
+	if index >= HardenedIndex {
+		data = append([]byte{0}, parentPriv...)
+	} else {
+		data = c.MarshalCompressed(c.ScalarBaseMult(kpar))
+	}
+	IL, IR := bip32HMAC(chainCode, data, index)
+	if IL.Cmp(c.N) >= 0 {
+		return nil, nil, ErrInvalidChildKey
+	}
+	k := IL.Add(IL, kpar)
+	k.Mod(k, c.N)
+	if k.Sign() == 0 {
+		return nil, nil, ErrInvalidChildKey
+	}
+	return c.MarshalPrivateKey(k), IR, nil
+}
+
+// CKDpub derives the child public key of parentPub, in the compressed form
+// of MarshalCompressed, at the non-hardened index, as in BIP-32; it matches
+// the public key of CKDpriv at the same index. It is an error if index is
+// hardened or parentPub is invalid, and ErrInvalidChildKey is returned as for
+// CKDpriv.
+func (c *Curve) CKDpub(parentPub, chainCode []byte, index uint32) (childPub, childChainCode []byte, err error) {
+	if index >= HardenedIndex {
+		return nil, nil, ErrHardenedPublic
+	}
+	px, py := c.UnmarshalCompressed(parentPub)
+	if px == nil || IsInfinity(px, py) {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	if len(chainCode) != 32 {
+		return nil, nil, ErrChainCode
+	}
+
+	IL, IR := bip32HMAC(chainCode, parentPub, index)
+	if IL.Cmp(c.N) >= 0 {
+		return nil, nil, ErrInvalidChildKey
+	}
+	x, y := c.ScalarBaseMult(IL)
+	x, y = c.Add(x, y, px, py)
+	if IsInfinity(x, y) {
+		return nil, nil, ErrInvalidChildKey
+	}
+	return c.MarshalCompressed(x, y), IR, nil
+}
@@ -0,0 +1,83 @@
+package ecc
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestCKD(t *testing.T) {
+	// BIP-32 test vector 1
+	curve := sampleCurves()["S256"]
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+	priv, chain := I[:32], I[32:]
+
+	path := []struct {
+		index       uint32
+		priv, chain string
+	}{
+		{0, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+			"873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508"},
+		{HardenedIndex, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+			"47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141"},
+		{1, "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+			"2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19"},
+		{HardenedIndex + 2, "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca",
+			"04466b9cc8e161e966409ca52986c584f07e9dc81f735db683c3ff6ec7b1503f"},
+	}
+	for i, step := range path {
+		if i > 0 {
+			parentPub := curve.MarshalCompressed(curve.ScalarBaseMult(new(big.Int).SetBytes(priv)))
+			parentChain := chain
+
+			var err error
+			priv, chain, err = curve.CKDpriv(priv, chain, step.index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pub, pubChain, err := curve.CKDpub(parentPub, parentChain, step.index)
+			if step.index >= HardenedIndex {
+				if err != ErrHardenedPublic {
+					t.Errorf("hardened CKDpub got: %v, want: %v", err, ErrHardenedPublic)
+				}
+			} else {
+				want := curve.MarshalCompressed(curve.ScalarBaseMult(new(big.Int).SetBytes(priv)))
+				if err != nil || !bytes.Equal(pub, want) || !bytes.Equal(pubChain, chain) {
+					t.Errorf("CKDpub step %d got: %x %x %v, want: %x %x", i, pub, pubChain, err, want, chain)
+				}
+			}
+		}
+		if got := hex.EncodeToString(priv); got != step.priv {
+			t.Errorf("step %d key got: %s, want: %s", i, got, step.priv)
+		}
+		if got := hex.EncodeToString(chain); got != step.chain {
+			t.Errorf("step %d chain code got: %s, want: %s", i, got, step.chain)
+		}
+	}
+
+	// m/0H/1 public key
+	want, _ := hex.DecodeString("03501e454bf00751f24b1b489aa925215d66af2234e3891c3b21a52bedb3cd711c")
+	k, _ := hex.DecodeString(path[2].priv)
+	if got := curve.MarshalCompressed(curve.ScalarBaseMult(new(big.Int).SetBytes(k))); !bytes.Equal(got, want) {
+		t.Errorf("m/0H/1 public key got: %x, want: %x", got, want)
+	}
+
+	if _, _, err := curve.CKDpriv(priv, chain[:31], 0); err != ErrChainCode {
+		t.Errorf("got: %v, want: %v", err, ErrChainCode)
+	}
+	if _, _, err := curve.CKDpriv(make([]byte, 32), chain, 0); err != ErrInvalidPrivateKey {
+		t.Errorf("got: %v, want: %v", err, ErrInvalidPrivateKey)
+	}
+	for _, pub := range [][]byte{{2}, {0}} {
+		if _, _, err := curve.CKDpub(pub, chain, 0); err != ErrInvalidPublicKey {
+			t.Errorf("%x: got: %v, want: %v", pub, err, ErrInvalidPublicKey)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package ecc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// ErrBoxOpen is returned when a box is malformed, was tampered with, or was
+// sealed to a different key.
+var ErrBoxOpen = errors.New("ecc: failed to open box")
+
+// boxKey derives the AES-256 key of a box from the ECDH shared secret and
+// both public keys.
+func (c *Curve) boxKey(shared, ephemeral, recipient []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte("ecc box"))
+	h.Write(shared)
+	h.Write(ephemeral)
+	h.Write(recipient)
+	return h.Sum(nil)
+}
+
+// SealBox encrypts plaintext to the public key (pubX, pubY), like libsodium's
+// crypto_box_seal. A fresh ephemeral key is agreed with the recipient by ECDH
+// and the plaintext is sealed with AES-256-GCM under a key derived from the
+// shared secret. The box is the compressed ephemeral public key followed by
+// the ciphertext, and is 16 bytes longer than plaintext plus the key.
+func (c *Curve) SealBox(pubX, pubY *big.Int, plaintext []byte) ([]byte, error) {
+	priv, ex, ey, err := c.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := c.ECDH(priv, pubX, pubY)
+	if err != nil {
+		return nil, err
+	}
+
+	epk := c.MarshalCompressed(ex, ey)
+	aead, err := newBoxAEAD(c.boxKey(shared, epk, c.MarshalCompressed(pubX, pubY)))
+	if err != nil {
+		return nil, err
+	}
+	// the key is never reused, so a zero nonce is safe
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(epk, nonce, plaintext, nil), nil
+}
+
+// OpenBox decrypts a box made by SealBox for the public key of priv.
+func (c *Curve) OpenBox(priv *big.Int, box []byte) ([]byte, error) {
+	n := 1 + (c.BitSize+7)/8
+	if len(box) < n {
+		return nil, ErrBoxOpen
+	}
+	epk := box[:n]
+	ex, ey := c.UnmarshalCompressed(epk)
+	if ex == nil {
+		return nil, ErrBoxOpen
+	}
+	shared, err := c.ECDH(priv, ex, ey)
+	if err != nil {
+		return nil, ErrBoxOpen
+	}
+
+	px, py := c.ScalarBaseMult(priv)
+	aead, err := newBoxAEAD(c.boxKey(shared, epk, c.MarshalCompressed(px, py)))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	plaintext, err := aead.Open(nil, nonce, box[n:], nil)
+	if err != nil {
+		return nil, ErrBoxOpen
+	}
+	return plaintext, nil
+}
+
+func newBoxAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,49 @@
+package ecc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestSealBox(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, x, y, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := []byte("attack at dawn")
+		box, err := curve.SealBox(x, y, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := curve.OpenBox(priv, box)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("got: %q, want: %q", got, msg)
+		}
+
+		for i := range box {
+			tampered := bytes.Clone(box)
+			tampered[i] ^= 1
+			if _, err := curve.OpenBox(priv, tampered); err != ErrBoxOpen {
+				t.Errorf("byte %d flipped: got: %v, want: %v", i, err, ErrBoxOpen)
+			}
+		}
+		if _, err := curve.OpenBox(priv, box[:len(box)-1]); err != ErrBoxOpen {
+			t.Errorf("truncated: got: %v, want: %v", err, ErrBoxOpen)
+		}
+
+		other, _, _, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other.Cmp(priv) != 0 {
+			if _, err := curve.OpenBox(other, box); err != ErrBoxOpen {
+				t.Errorf("wrong key: got: %v, want: %v", err, ErrBoxOpen)
+			}
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package ecc
+
+import "math/big"
+
+// cmRounds is the number of random points used to single out the trace
+// among the candidates allowed by complex multiplication.
+const cmRounds = 8
+
+// cmTraces returns the possible traces of Frobenius for j = 0 (A = 0) or
+// j = 1728 (B = 0) curves, or nil if the curve has neither.
+func (c *Curve) cmTraces() []*big.Int {
+	p := c.P
+	var d *big.Int
+	switch {
+	case c.A.Sign() == 0 && c.B.Sign() != 0:
+		if new(big.Int).Mod(p, big.NewInt(3)).Int64() == 2 {
+			return []*big.Int{new(big.Int)}
+		}
+		d = big.NewInt(3)
+	case c.B.Sign() == 0 && c.A.Sign() != 0:
+		if new(big.Int).Mod(p, big.NewInt(4)).Int64() == 3 {
+			return []*big.Int{new(big.Int)}
+		}
+		d = big.NewInt(1)
+	default:
+		return nil
+	}
+	if p.Cmp(d) <= 0 {
+		return nil
+	}
+
+	x, y, ok := Cornacchia(d, p)
+	if !ok {
+		return nil
+	}
+
+	var ts []*big.Int
+	if d.Int64() == 3 {
+		// 4p = t² + 3v² with t = 2x, v = 2y
+		y3 := new(big.Int).Mul(y, d)
+		ts = []*big.Int{
+			new(big.Int).Lsh(x, 1),
+			new(big.Int).Add(x, y3),
+			new(big.Int).Sub(x, y3),
+		}
+	} else {
+		// 4p = t² + 4v² with t = 2x, v = y
+		ts = []*big.Int{
+			new(big.Int).Lsh(x, 1),
+			new(big.Int).Lsh(y, 1),
+		}
+	}
+	for _, t := range ts[:len(ts):len(ts)] {
+		ts = append(ts, new(big.Int).Neg(t))
+	}
+	return ts
+}
+
+// cmOrder returns the number of points of a j = 0 or j = 1728 curve, found
+// from the traces allowed by its complex multiplication. ok is false if the
+// curve has other j or no unique candidate annihilates random points.
+func (c *Curve) cmOrder() (n *big.Int, ok bool) {
+	if c.A == nil || c.B == nil {
+		return nil, false
+	}
+	ts := c.cmTraces()
+	if ts == nil {
+		return nil, false
+	}
+
+	p1 := new(big.Int).Add(c.P, big.NewInt(1))
+	cands := make([]*big.Int, len(ts))
+	for i, t := range ts {
+		cands[i] = new(big.Int).Sub(p1, t)
+	}
+	if len(cands) == 1 {
+		return cands[0], true
+	}
+
+	for i := 0; i < cmRounds && len(cands) > 1; i++ {
+		x, y, err := c.randomPoint(c.random())
+		if err != nil {
+			return nil, false
+		}
+		var left []*big.Int
+		for _, n := range cands {
+			if qx, qy := c.ScalarMult(x, y, n); IsInfinity(qx, qy) {
+				left = append(left, n)
+			}
+		}
+		cands = left
+	}
+	if len(cands) != 1 {
+		return nil, false
+	}
+	return cands[0], true
+}
@@ -0,0 +1,59 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCMOrder(t *testing.T) {
+	count := func(c *Curve) int64 {
+		n := int64(1)
+		for x := big.NewInt(0); x.Cmp(c.P) < 0; x.Add(x, big.NewInt(1)) {
+			n += int64(1 + big.Jacobi(c.evaluatePolynomial(x), c.P))
+		}
+		return n
+	}
+
+	for _, p := range []int64{29, 31, 97, 101, 103, 1009, 10007} {
+		for k := int64(1); k <= 6; k++ {
+			for _, c := range []*Curve{
+				{P: big.NewInt(p), A: big.NewInt(0), B: big.NewInt(k)},
+				{P: big.NewInt(p), A: big.NewInt(k), B: big.NewInt(0)},
+			} {
+				n, ok := c.cmOrder()
+				if !ok {
+					// rare: several candidates survived, Schoof takes over
+					continue
+				}
+				if want := count(c); n.Int64() != want {
+					t.Errorf("%s over F_%d: got: %d, want: %d", c.poly(), p, n, want)
+				}
+			}
+		}
+	}
+
+	if _, ok := (&Curve{P: big.NewInt(97), A: big.NewInt(2), B: big.NewInt(3)}).cmOrder(); ok {
+		t.Error("cmOrder succeeded on a curve with j ≠ 0, 1728")
+	}
+}
+
+func TestSchoofCM(t *testing.T) {
+	s := sampleCurves()["S256"]
+	c := &Curve{P: s.P, A: s.A, B: s.B}
+	n, err := c.Schoof()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Cmp(s.N) != 0 {
+		t.Errorf("got: %d, want: %d", n, s.N)
+	}
+
+	c = &Curve{P: big.NewInt(1009), A: big.NewInt(0), B: big.NewInt(5)}
+	want, err := c.SchoofWithProgress(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := c.Schoof(); err != nil || n.Cmp(want) != 0 {
+		t.Errorf("got: %d, %v, want: %d", n, err, want)
+	}
+}
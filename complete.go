@@ -0,0 +1,167 @@
+package ecc
+
+import "math/big"
+
+// The complete addition formulas below work on homogeneous projective
+// coordinates: (X:Y:Z) represents x = X/Z and y = Y/Z, and the Point at
+// infinity is (0:1:0). They handle P+Q, P+P, P+(-P) and P+∞ alike. Curves
+// with Projective set do all their point arithmetic in these coordinates.
+
+// projectiveForAffine returns projective coordinates for the affine Point
+// (x, y), mapping the conventional (0, 0) to (0:1:0).
+func projectiveForAffine(x, y *big.Int) (*big.Int, *big.Int, *big.Int) {
+	if IsInfinity(x, y) {
+		return new(big.Int), big.NewInt(1), new(big.Int)
+	}
+	return new(big.Int).Set(x), new(big.Int).Set(y), big.NewInt(1)
+}
+
+// affineFromProjective reverses the projective transform. If the Point is ∞
+// it returns 0, 0.
+func (c *Curve) affineFromProjective(x, y, z *big.Int) (xOut, yOut *big.Int) {
+	if z.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	P := c.P
+	zinv := new(big.Int).ModInverse(z, P)
+
+	xOut = new(big.Int).Mul(x, zinv)
+	xOut.Mod(xOut, P)
+	yOut = new(big.Int).Mul(y, zinv)
+	yOut.Mod(yOut, P)
+	return
+}
+
+// AddComplete returns the sum of (x1,y1) and (x2,y2) like Add, but with the
+// complete addition formulas, so that no input takes a different branch.
+func (c *Curve) AddComplete(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	panicIfNotOnCurve(c, x1, y1)
+	panicIfNotOnCurve(c, x2, y2)
+
+	X1, Y1, Z1 := projectiveForAffine(x1, y1)
+	X2, Y2, Z2 := projectiveForAffine(x2, y2)
+	return c.affineFromProjective(c.addProjective(X1, Y1, Z1, X2, Y2, Z2))
+}
+
+// addProjective takes two points in projective coordinates, (x1, y1, z1) and
+// (x2, y2, z2) and returns their sum, also in projective form.
+func (c *Curve) addProjective(x1, y1, z1, x2, y2, z2 *big.Int) (x3, y3, z3 *big.Int) {
+	// See https://eprint.iacr.org/2015/1060.pdf, Algorithm 1
+	P := c.P
+	a := c.A
+	b3 := new(big.Int).Mul(c.B, big.NewInt(3))
+	b3.Mod(b3, P)
+
+	mod := func(z *big.Int) *big.Int {
+		return z.Mod(z, P)
+	}
+
+	t0 := mod(new(big.Int).Mul(x1, x2))
+	t1 := mod(new(big.Int).Mul(y1, y2))
+	t2 := mod(new(big.Int).Mul(z1, z2))
+	t3 := new(big.Int).Add(x1, y1)
+	t4 := new(big.Int).Add(x2, y2)
+	mod(t3.Mul(t3, t4))
+	t4.Add(t0, t1)
+	mod(t3.Sub(t3, t4))
+	t4.Add(x1, z1)
+	t5 := new(big.Int).Add(x2, z2)
+	mod(t4.Mul(t4, t5))
+	t5.Add(t0, t2)
+	mod(t4.Sub(t4, t5))
+	t5.Add(y1, z1)
+	x3 = new(big.Int).Add(y2, z2)
+	mod(t5.Mul(t5, x3))
+	x3.Add(t1, t2)
+	mod(t5.Sub(t5, x3))
+	z3 = mod(new(big.Int).Mul(a, t4))
+	mod(x3.Mul(b3, t2))
+	mod(z3.Add(x3, z3))
+	mod(x3.Sub(t1, z3))
+	mod(z3.Add(t1, z3))
+	y3 = mod(new(big.Int).Mul(x3, z3))
+	t1.Add(t0, t0)
+	mod(t1.Add(t1, t0))
+	mod(t2.Mul(a, t2))
+	mod(t4.Mul(b3, t4))
+	mod(t1.Add(t1, t2))
+	mod(t2.Sub(t0, t2))
+	mod(t2.Mul(a, t2))
+	mod(t4.Add(t4, t2))
+	mod(t0.Mul(t1, t4))
+	mod(y3.Add(y3, t0))
+	mod(t0.Mul(t5, t4))
+	mod(x3.Mul(t3, x3))
+	mod(x3.Sub(x3, t0))
+	mod(t0.Mul(t3, t1))
+	mod(z3.Mul(t5, z3))
+	mod(z3.Add(z3, t0))
+
+	return
+}
+
+// doubleProjective takes a Point in projective coordinates, (x, y, z), and
+// returns its double, also in projective form.
+func (c *Curve) doubleProjective(x, y, z *big.Int) (x3, y3, z3 *big.Int) {
+	// See https://eprint.iacr.org/2015/1060.pdf, Algorithm 3
+	P := c.P
+	a := c.A
+	b3 := new(big.Int).Mul(c.B, big.NewInt(3))
+	b3.Mod(b3, P)
+
+	mod := func(z *big.Int) *big.Int {
+		return z.Mod(z, P)
+	}
+
+	t0 := mod(new(big.Int).Mul(x, x))
+	t1 := mod(new(big.Int).Mul(y, y))
+	t2 := mod(new(big.Int).Mul(z, z))
+	t3 := mod(new(big.Int).Mul(x, y))
+	mod(t3.Add(t3, t3))
+	z3 = mod(new(big.Int).Mul(x, z))
+	mod(z3.Add(z3, z3))
+	x3 = mod(new(big.Int).Mul(a, z3))
+	y3 = mod(new(big.Int).Mul(b3, t2))
+	mod(y3.Add(x3, y3))
+	mod(x3.Sub(t1, y3))
+	mod(y3.Add(t1, y3))
+	mod(y3.Mul(x3, y3))
+	mod(x3.Mul(t3, x3))
+	mod(z3.Mul(b3, z3))
+	mod(t2.Mul(a, t2))
+	mod(t3.Sub(t0, t2))
+	mod(t3.Mul(a, t3))
+	mod(t3.Add(t3, z3))
+	mod(z3.Add(t0, t0))
+	mod(t0.Add(z3, t0))
+	mod(t0.Add(t0, t2))
+	mod(t0.Mul(t0, t3))
+	mod(y3.Add(y3, t0))
+	t2.Mul(y, z)
+	mod(t2.Add(t2, t2))
+	mod(t0.Mul(t2, t3))
+	mod(x3.Sub(x3, t0))
+	mod(z3.Mul(t2, t1))
+	mod(z3.Add(z3, z3))
+	mod(z3.Add(z3, z3))
+
+	return
+}
+
+// scalarMultProjective is ScalarMult in projective coordinates.
+func (c *Curve) scalarMultProjective(Bx, By, k *big.Int) (*big.Int, *big.Int) {
+	X, Y, Z := projectiveForAffine(Bx, By)
+	nY := c.ReduceField(new(big.Int).Neg(Y))
+	x, y, z := new(big.Int), big.NewInt(1), new(big.Int)
+	digits := naf(new(big.Int).Abs(k))
+	for i := len(digits) - 1; i >= 0; i-- {
+		x, y, z = c.doubleProjective(x, y, z)
+		switch digits[i] {
+		case 1:
+			x, y, z = c.addProjective(X, Y, Z, x, y, z)
+		case -1:
+			x, y, z = c.addProjective(X, nY, Z, x, y, z)
+		}
+	}
+	return c.affineFromProjective(x, y, z)
+}
@@ -0,0 +1,131 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestAddComplete(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		_, x1, y1, _ := curve.GenerateKey(rand.Reader)
+		_, x2, y2, _ := curve.GenerateKey(rand.Reader)
+		nx, ny := curve.Neg(x1, y1)
+		inf := new(big.Int)
+
+		cases := []struct {
+			name           string
+			x1, y1, x2, y2 *big.Int
+		}{
+			{"P+Q", x1, y1, x2, y2},
+			{"P+P", x1, y1, x1, y1},
+			{"P+(-P)", x1, y1, nx, ny},
+			{"P+∞", x1, y1, inf, inf},
+			{"∞+P", inf, inf, x1, y1},
+			{"∞+∞", inf, inf, inf, inf},
+		}
+		for _, c := range cases {
+			gx, gy := curve.AddComplete(c.x1, c.y1, c.x2, c.y2)
+			wx, wy := curve.Add(c.x1, c.y1, c.x2, c.y2)
+			if gx.Cmp(wx) != 0 || gy.Cmp(wy) != 0 {
+				t.Errorf("%s: got: (%d,%d), want: (%d,%d)", c.name, gx, gy, wx, wy)
+			}
+		}
+	})
+}
+
+func TestAddCompleteTwoTorsion(t *testing.T) {
+	// (4361, 0) has order 2
+	curve := sampleCurves()["COFACTOR"]
+	x, y := curve.AddComplete(big.NewInt(4361), big.NewInt(0), big.NewInt(4361), big.NewInt(0))
+	if x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("got: (%d,%d), want: ∞", x, y)
+	}
+}
+
+func TestProjective(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		proj := *curve
+		proj.Projective = true
+		inf := new(big.Int)
+
+		for i := 0; i < 5; i++ {
+			_, x1, y1, _ := curve.GenerateKey(rand.Reader)
+			_, x2, y2, _ := curve.GenerateKey(rand.Reader)
+			nx, ny := curve.Neg(x1, y1)
+			k, _ := rand.Int(rand.Reader, curve.N)
+
+			for _, c := range []struct {
+				name           string
+				x1, y1, x2, y2 *big.Int
+			}{
+				{"P+Q", x1, y1, x2, y2},
+				{"P+P", x1, y1, x1, y1},
+				{"P+(-P)", x1, y1, nx, ny},
+				{"P+∞", x1, y1, inf, inf},
+				{"∞+∞", inf, inf, inf, inf},
+			} {
+				gx, gy := proj.Add(c.x1, c.y1, c.x2, c.y2)
+				wx, wy := curve.Add(c.x1, c.y1, c.x2, c.y2)
+				if gx.Cmp(wx) != 0 || gy.Cmp(wy) != 0 {
+					t.Errorf("Add %s: got: (%d,%d), want: (%d,%d)", c.name, gx, gy, wx, wy)
+				}
+			}
+
+			for _, pt := range [][2]*big.Int{{x1, y1}, {inf, inf}} {
+				gx, gy := proj.Double(pt[0], pt[1])
+				wx, wy := curve.Double(pt[0], pt[1])
+				if gx.Cmp(wx) != 0 || gy.Cmp(wy) != 0 {
+					t.Errorf("Double (%d,%d): got: (%d,%d), want: (%d,%d)", pt[0], pt[1], gx, gy, wx, wy)
+				}
+			}
+
+			for _, k := range []*big.Int{k, big.NewInt(0), big.NewInt(1), curve.N, new(big.Int).Neg(k)} {
+				gx, gy := proj.ScalarMult(x1, y1, k)
+				wx, wy := curve.ScalarMult(x1, y1, k)
+				if gx.Cmp(wx) != 0 || gy.Cmp(wy) != 0 {
+					t.Errorf("ScalarMult %d: got: (%d,%d), want: (%d,%d)", k, gx, gy, wx, wy)
+				}
+			}
+
+			priv, pubX, pubY, _ := curve.GenerateKey(rand.Reader)
+			hash := []byte("testing")
+			r, s := proj.Sign(priv, hash)
+			if !curve.Verify(pubX, pubY, hash, r, s) || !proj.Verify(pubX, pubY, hash, r, s) {
+				t.Errorf("signature made with projective coordinates doesn't verify")
+			}
+		}
+	})
+}
+
+func TestProjectiveTwoTorsion(t *testing.T) {
+	// (4361, 0) has order 2
+	curve := sampleCurves()["COFACTOR"]
+	curve.Projective = true
+	x, y := curve.Double(big.NewInt(4361), big.NewInt(0))
+	if x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("got: (%d,%d), want: ∞", x, y)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	curve := sampleCurves()["S256"]
+	priv, pubX, pubY, _ := curve.GenerateKey(rand.Reader)
+	hash := []byte("testing")
+	r, s := curve.Sign(priv, hash)
+
+	for _, projective := range []bool{false, true} {
+		name := "Jacobian"
+		if projective {
+			name = "Projective"
+		}
+		b.Run(name, func(b *testing.B) {
+			curve.Projective = projective
+			for i := 0; i < b.N; i++ {
+				if !curve.Verify(pubX, pubY, hash, r, s) {
+					b.Fatal("verify failed")
+				}
+			}
+		})
+	}
+}
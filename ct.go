@@ -0,0 +1,75 @@
+package ecc
+
+import "math/big"
+
+// selectWord returns b if cond is 1 and a if cond is 0, without branching.
+func selectWord(cond uint, a, b big.Word) big.Word {
+	mask := big.Word(-cond)
+	return a ^ (mask & (a ^ b))
+}
+
+// selectInt returns a copy of b if cond is 1 and of a if cond is 0. Both are
+// read in full, limb by limb, whatever cond is.
+func selectInt(cond uint, a, b *big.Int) *big.Int {
+	aw, bw := a.Bits(), b.Bits()
+	n := len(aw)
+	if len(bw) > n {
+		n = len(bw)
+	}
+	r := make([]big.Word, n)
+	for i := 0; i < n; i++ {
+		var x, y big.Word
+		if i < len(aw) {
+			x = aw[i]
+		}
+		if i < len(bw) {
+			y = bw[i]
+		}
+		r[i] = selectWord(cond, x, y)
+	}
+	return new(big.Int).SetBits(r)
+}
+
+// selectPoint returns a copy of b if cond is 1 and of a if cond is 0,
+// masking the limbs of every coordinate of both instead of branching on
+// cond. The coordinates must be non-negative.
+func selectPoint(cond int, a, b [3]*big.Int) [3]*big.Int {
+	c := uint(cond) & 1
+	return [3]*big.Int{
+		selectInt(c, a[0], b[0]),
+		selectInt(c, a[1], b[1]),
+		selectInt(c, a[2], b[2]),
+	}
+}
+
+// ScalarMultCT returns k*(Bx,By) with a Montgomery ladder: every bit of k,
+// up to the bit length of N, costs one addition and one doubling, and the
+// ladder's points are swapped with selectPoint rather than by branching on
+// the bit. This removes the dependence of the sequence of operations on k
+// that ScalarMult has; math/big arithmetic itself is not constant-time, so
+// timing may still leak some information. As with ScalarMult, a negative k
+// gives -(|k|*(Bx,By)).
+func (c *Curve) ScalarMultCT(Bx, By, k *big.Int) (*big.Int, *big.Int) {
+	panicIfNotOnCurve(c, Bx, By)
+
+	if k.Sign() < 0 {
+		return c.Neg(c.ScalarMultCT(Bx, By, new(big.Int).Neg(k)))
+	}
+	n := c.N.BitLen()
+	if k.BitLen() > n {
+		n = k.BitLen()
+	}
+
+	r0 := [3]*big.Int{new(big.Int), new(big.Int), new(big.Int)}
+	r1 := [3]*big.Int{new(big.Int).Set(Bx), new(big.Int).Set(By), zForAffine(Bx, By)}
+	for i := n - 1; i >= 0; i-- {
+		bit := int(k.Bit(i))
+		r0, r1 = selectPoint(bit, r0, r1), selectPoint(bit, r1, r0)
+		x, y, z := c.addJacobian(r0[0], r0[1], r0[2], r1[0], r1[1], r1[2])
+		r1 = [3]*big.Int{x, y, z}
+		x, y, z = c.doubleJacobian(r0[0], r0[1], r0[2])
+		r0 = [3]*big.Int{x, y, z}
+		r0, r1 = selectPoint(bit, r0, r1), selectPoint(bit, r1, r0)
+	}
+	return c.affineFromJacobian(r0[0], r0[1], r0[2])
+}
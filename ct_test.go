@@ -0,0 +1,111 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestSelectPoint(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		for i := 0; i < 10; i++ {
+			_, ax, ay, _ := curve.GenerateKey(rand.Reader)
+			_, bx, by, _ := curve.GenerateKey(rand.Reader)
+			az, _ := rand.Int(rand.Reader, curve.P)
+			bz, _ := rand.Int(rand.Reader, curve.P)
+			a := [3]*big.Int{ax, ay, az}
+			b := [3]*big.Int{bx, by, bz}
+
+			for cond, want := range [][3]*big.Int{a, b} {
+				got := selectPoint(cond, a, b)
+				for j := range got {
+					if got[j].Cmp(want[j]) != 0 {
+						t.Errorf("cond %d coordinate %d got: %v, want: %v", cond, j, got[j], want[j])
+					}
+					// the result is always a fresh copy, never one of the inputs
+					if got[j] == a[j] || got[j] == b[j] {
+						t.Errorf("cond %d coordinate %d aliases an input", cond, j)
+					}
+				}
+			}
+		}
+
+		// inputs of different lengths, including zero
+		zero := [3]*big.Int{new(big.Int), new(big.Int), new(big.Int)}
+		one := [3]*big.Int{curve.Gx, curve.Gy, big.NewInt(1)}
+		for cond, want := range [][3]*big.Int{zero, one} {
+			got := selectPoint(cond, zero, one)
+			for j := range got {
+				if got[j].Cmp(want[j]) != 0 {
+					t.Errorf("cond %d coordinate %d got: %v, want: %v", cond, j, got[j], want[j])
+				}
+			}
+		}
+	})
+}
+
+func TestSelectReadsBoth(t *testing.T) {
+	// the result is built over the limbs of the longer input whichever is
+	// chosen, so its capacity shows that both were walked
+	short := big.NewInt(7)
+	long := new(big.Int).Lsh(big.NewInt(1), 300)
+	n := len(long.Bits())
+	for cond := uint(0); cond <= 1; cond++ {
+		for _, in := range [][2]*big.Int{{short, long}, {long, short}} {
+			if got := selectInt(cond, in[0], in[1]); cap(got.Bits()) != n {
+				t.Errorf("cond %d: walked %d limbs, want: %d", cond, cap(got.Bits()), n)
+			}
+		}
+	}
+
+	// the input not chosen is still read: a nil one panics either way
+	mustPanic := func(name string, f func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: the unselected input was not read", name)
+			}
+		}()
+		f()
+	}
+	p := [3]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	for j := 0; j < 3; j++ {
+		q := p
+		q[j] = nil
+		mustPanic("cond 0", func() { selectPoint(0, p, q) })
+		mustPanic("cond 1", func() { selectPoint(1, q, p) })
+	}
+}
+
+func TestScalarMultCT(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		ks := []*big.Int{
+			big.NewInt(0),
+			big.NewInt(1),
+			big.NewInt(2),
+			new(big.Int).Sub(curve.N, big.NewInt(1)),
+			new(big.Int).Set(curve.N),
+			big.NewInt(-5),
+		}
+		for i := 0; i < 5; i++ {
+			k, _ := rand.Int(rand.Reader, curve.N)
+			ks = append(ks, k)
+		}
+		for _, k := range ks {
+			wx, wy := curve.ScalarMult(curve.Gx, curve.Gy, k)
+			x, y := curve.ScalarMultCT(curve.Gx, curve.Gy, k)
+			if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+				t.Errorf("k = %v got: (%v, %v), want: (%v, %v)", k, x, y, wx, wy)
+			}
+		}
+	})
+}
+
+func BenchmarkScalarMultCT(b *testing.B) {
+	curve := sampleCurves()["S256"]
+	k, _ := rand.Int(rand.Reader, curve.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		curve.ScalarMultCT(curve.Gx, curve.Gy, k)
+	}
+}
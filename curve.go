@@ -1,11 +1,23 @@
 package ecc
 
 import (
+	"context"
 	"crypto/rand"
+	"errors"
 	"io"
 	"math/big"
 )
 
+var (
+	ErrNotOnCurve    = errors.New("ecc: point is not on the curve")
+	ErrSingularCurve = errors.New("ecc: singular curve")
+	ErrInvalidScalar = errors.New("ecc: invalid scalar")
+	ErrInvalidOrder  = errors.New("ecc: base point does not have the given order")
+	ErrNoBasePoint   = errors.New("ecc: curve has no base point")
+	ErrPointEncoding = errors.New("ecc: invalid point encoding")
+	ErrMissingParam  = errors.New("ecc: missing curve parameter")
+)
+
 // The elliptic curve E is in Weierstrass form y^2=poly(x)=x^3+Ax+B
 // This package operates, internally, on Jacobian coordinates. For a given
 // (x, y) position on the curve, the Jacobian coordinates are (x1, y1, z1)
@@ -20,7 +32,8 @@ import (
 //
 // Note that the conventional Point at infinity (0, 0) is not considered on the
 // curve, although it can be returned by Add, Double, ScalarMult, or
-// ScalarBaseMult (but not the Unmarshal or UnmarshalCompressed functions).
+// ScalarBaseMult, and by UnmarshalCompressed for its one-byte encoding, but
+// not by Unmarshal.
 type Curve struct {
 	P       *big.Int       // the order of the underlying field
 	A       *big.Int       // the constant of the Curve equation
@@ -30,7 +43,117 @@ type Curve struct {
 	H       *big.Int       // the cofactor of the subgroup
 	BitSize int            // the size of the underlying field
 	Name    string         // the canonical name of the curve
+	Seed    []byte         // the seed the curve was generated from, if any
 	dpCache map[int64]Poly // division polynomial
+	order   *big.Int       // #E, if given by SetOrder
+
+	// SkipValidation turns off the on-curve checks of Add, Double,
+	// ScalarMult and the other point operations. It is DANGEROUS: with it,
+	// an off-curve input is silently processed on some other curve, which
+	// can leak the scalar to an attacker. Only set it when every point has
+	// already been validated.
+	SkipValidation bool
+
+	// Projective switches Add, Double and ScalarMult, and so everything
+	// built on them, from Jacobian to homogeneous projective coordinates
+	// with complete formulas, which have no exceptional cases to branch on.
+	Projective bool
+
+	// Rand is the source of randomness of the randomized algorithms that
+	// don't take one as an argument, like PollardRho, GroupStructure and
+	// Schoof's CM shortcut. If nil, crypto/rand.Reader is used. Setting it
+	// to a fixed stream makes them reproducible.
+	Rand io.Reader
+}
+
+// NewCurve returns the curve y² = x³ + ax + b over F_p with base Point
+// (gx, gy) of order n and cofactor h, with BitSize set to the bit length of p.
+// It is an error if the curve is singular, the base Point is not on it, or
+// n·G is not the Point at infinity. h may be nil if it is unknown; a nil p,
+// a or b is ErrMissingParam, a nil gx or gy ErrNoBasePoint and a nil n
+// ErrInvalidOrder.
+func NewCurve(p, a, b, gx, gy, n, h *big.Int, name string) (*Curve, error) {
+	switch {
+	case p == nil || a == nil || b == nil:
+		return nil, ErrMissingParam
+	case gx == nil || gy == nil:
+		return nil, ErrNoBasePoint
+	case n == nil:
+		return nil, ErrInvalidOrder
+	}
+	c := &Curve{
+		P:       p,
+		A:       a,
+		B:       b,
+		Gx:      gx,
+		Gy:      gy,
+		N:       n,
+		H:       h,
+		BitSize: p.BitLen(),
+		Name:    name,
+	}
+	if _, err := c.JInvariant(); err != nil {
+		return nil, err
+	}
+	if !c.IsOnCurve(gx, gy) {
+		return nil, ErrNotOnCurve
+	}
+	if n.Sign() <= 0 {
+		return nil, ErrInvalidOrder
+	}
+	if x, y := c.ScalarBaseMult(n); !IsInfinity(x, y) {
+		return nil, ErrInvalidOrder
+	}
+	return c, nil
+}
+
+// OrderBytes returns the length in bytes of the order of the base Point.
+func (c *Curve) OrderBytes() int {
+	return (c.N.BitLen() + 7) / 8
+}
+
+// ReduceField returns x modulo P, in [0, P).
+func (c *Curve) ReduceField(x *big.Int) *big.Int {
+	return new(big.Int).Mod(x, c.P)
+}
+
+// ReduceScalar returns k modulo N, in [0, N).
+func (c *Curve) ReduceScalar(k *big.Int) *big.Int {
+	return new(big.Int).Mod(k, c.N)
+}
+
+// NegateScalar returns -k modulo N, in [0, N), so that NegateScalar(k)·G is
+// the negation of k·G. Protocols that insist on a public key or nonce Point
+// with an even y-coordinate, like BIP-340 Schnorr signatures, use it to swap
+// a secret scalar for its negation when the Point has an odd one.
+func (c *Curve) NegateScalar(k *big.Int) *big.Int {
+	r := c.ReduceScalar(k)
+	if r.Sign() != 0 {
+		r.Sub(c.N, r)
+	}
+	return r
+}
+
+// ScalarFromBytes converts b, in big-endian or little-endian byte order, into
+// a scalar reduced modulo N. It is an error if b is empty or longer than twice
+// OrderBytes, or the scalar is zero modulo N.
+func (c *Curve) ScalarFromBytes(b []byte, littleEndian bool) (*big.Int, error) {
+	if len(b) == 0 || len(b) > 2*c.OrderBytes() {
+		return nil, ErrInvalidScalar
+	}
+	if littleEndian {
+		be := make([]byte, len(b))
+		for i := range b {
+			be[len(b)-1-i] = b[i]
+		}
+		b = be
+	}
+
+	k := c.ReduceScalar(new(big.Int).SetBytes(b))
+	if k.Sign() == 0 {
+		return nil, ErrInvalidScalar
+	}
+	return k, nil
 }
 
 // evaluatePolynomial returns y² = x³ + ax + b.
@@ -43,6 +166,34 @@ func (c *Curve) evaluatePolynomial(x *big.Int) *big.Int {
 	return x3
 }
 
+// Discriminant returns Δ = -16(4A³ + 27B²) mod P. The curve is singular if
+// and only if Δ is zero.
+func (c *Curve) Discriminant() *big.Int {
+	P := c.P
+	d := new(big.Int).Exp(c.A, big.NewInt(3), P)
+	d.Lsh(d, 2)
+	b2 := new(big.Int).Mul(c.B, c.B)
+	d.Add(d, b2.Mul(b2, big.NewInt(27)))
+	d.Mul(d, big.NewInt(-16))
+	return d.Mod(d, P)
+}
+
+// JInvariant returns the j-invariant -1728·(4A)³/Δ = 1728·4A³/(4A³+27B²) of
+// the curve. It is an error if the curve is singular.
+func (c *Curve) JInvariant() (*big.Int, error) {
+	P := c.P
+	d := c.Discriminant()
+	if d.Sign() == 0 {
+		return nil, ErrSingularCurve
+	}
+
+	j := new(big.Int).Lsh(c.A, 2)
+	j.Exp(j, big.NewInt(3), P)
+	j.Mul(j, big.NewInt(-1728))
+	j.Mul(j, d.ModInverse(d, P))
+	return j.Mod(j, P), nil
+}
+
 // IsOnCurve reports whether the given (x,y) lies on the curve.
 func (c *Curve) IsOnCurve(x, y *big.Int) bool {
 	P := c.P
@@ -56,14 +207,71 @@ func (c *Curve) IsOnCurve(x, y *big.Int) bool {
 	return c.evaluatePolynomial(x).Cmp(y2) == 0
 }
 
+// InSubgroup reports whether (x, y) lies on the curve and in the subgroup of
+// order N generated by the base Point. When H is 1 the subgroup is the whole
+// group, so being on the curve is enough; otherwise N·(x, y) must be ∞. The
+// Point at infinity itself is not accepted.
+func (c *Curve) InSubgroup(x, y *big.Int) bool {
+	if !c.IsOnCurve(x, y) {
+		return false
+	}
+	if c.H != nil && c.H.Cmp(big.NewInt(1)) == 0 {
+		return true
+	}
+	nx, ny := c.ScalarMult(x, y, c.N)
+	return IsInfinity(nx, ny)
+}
+
 // Neg returns the inverse of Point (x, y), which is the Point (x, -y)
 func (c *Curve) Neg(x, y *big.Int) (*big.Int, *big.Int) {
 	panicIfNotOnCurve(c, x, y)
 
-	ny := new(big.Int).Neg(y)
-	ny.Mod(ny, c.P)
+	return new(big.Int).Set(x), c.ReduceField(new(big.Int).Neg(y))
+}
+
+// AreNegatives reports whether (x1, y1) = -(x2, y2), that is, x1 = x2 and
+// y1 = P - y2. The Point at infinity is its own negative and no other's,
+// and so is a Point of order two.
+func (c *Curve) AreNegatives(x1, y1, x2, y2 *big.Int) bool {
+	if inf1, inf2 := IsInfinity(x1, y1), IsInfinity(x2, y2); inf1 || inf2 {
+		return inf1 && inf2
+	}
+	if x1.Cmp(x2) != 0 {
+		return false
+	}
+	s := new(big.Int).Add(y1, y2)
+	return s.Mod(s, c.P).Sign() == 0
+}
+
+// NegChecked is like Neg, but returns ErrNotOnCurve instead of panicking if
+// (x, y) is neither on the curve nor the Point at infinity.
+func (c *Curve) NegChecked(x, y *big.Int) (*big.Int, *big.Int, error) {
+	if !IsInfinity(x, y) && !c.IsOnCurve(x, y) {
+		return nil, nil, ErrNotOnCurve
+	}
+	nx, ny := c.Neg(x, y)
+	return nx, ny, nil
+}
+
+// IsInfinity reports whether (x, y) is (0, 0), which Add, Double, ScalarMult
+// and the other point operations use for the Point at infinity.
+func IsInfinity(x, y *big.Int) bool {
+	return x.Sign() == 0 && y.Sign() == 0
+}
 
-	return new(big.Int).Set(x), ny
+// HasBasePoint reports whether Gx, Gy and N are set. Curves used only for
+// point counting, like those given to Schoof, may lack them, and key
+// generation and signing return ErrNoBasePoint on such curves.
+func (c *Curve) HasBasePoint() bool {
+	return c.Gx != nil && c.Gy != nil && c.N != nil
+}
+
+// random returns Rand, or crypto/rand.Reader if it is nil.
+func (c *Curve) random() io.Reader {
+	if c.Rand != nil {
+		return c.Rand
+	}
+	return rand.Reader
 }
 
 // zForAffine returns a Jacobian Z value for the affine Point (x, y). If x and
@@ -71,7 +279,7 @@ func (c *Curve) Neg(x, y *big.Int) (*big.Int, *big.Int) {
 // 0) is not on any of the curves handled here.
 func zForAffine(x, y *big.Int) *big.Int {
 	z := new(big.Int)
-	if x.Sign() != 0 || y.Sign() != 0 {
+	if !IsInfinity(x, y) {
 		z.SetInt64(1)
 	}
 	return z
@@ -95,11 +303,53 @@ func (c *Curve) affineFromJacobian(x, y, z *big.Int) (xOut, yOut *big.Int) {
 	return
 }
 
+// batchAffineFromJacobian reverses the Jacobian transform of many points at
+// once, sharing a single inversion. Points at ∞ are returned as 0, 0.
+func (c *Curve) batchAffineFromJacobian(points [][3]*big.Int) [][2]*big.Int {
+	P := c.P
+	ret := make([][2]*big.Int, len(points))
+	var idx []int
+	var zs []*big.Int
+	for i, pt := range points {
+		if pt[2].Sign() == 0 {
+			ret[i] = [2]*big.Int{new(big.Int), new(big.Int)}
+			continue
+		}
+		idx = append(idx, i)
+		zs = append(zs, pt[2])
+	}
+
+	// with ∞ left out, the product of zs is invertible and BatchModInverse
+	// takes its single-inversion path
+	for j, zinv := range BatchModInverse(zs, P) {
+		i := idx[j]
+		if zinv == nil {
+			ret[i] = [2]*big.Int{new(big.Int), new(big.Int)}
+			continue
+		}
+		zinvsq := new(big.Int).Mul(zinv, zinv)
+		xOut := new(big.Int).Mul(points[i][0], zinvsq)
+		xOut.Mod(xOut, P)
+		zinvsq.Mul(zinvsq, zinv)
+		yOut := new(big.Int).Mul(points[i][1], zinvsq)
+		yOut.Mod(yOut, P)
+		ret[i] = [2]*big.Int{xOut, yOut}
+	}
+
+	return ret
+}
+
 // Add returns the sum of (x1,y1) and (x2,y2)
 func (c *Curve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
 	panicIfNotOnCurve(c, x1, y1)
 	panicIfNotOnCurve(c, x2, y2)
 
+	if c.Projective {
+		X1, Y1, Z1 := projectiveForAffine(x1, y1)
+		X2, Y2, Z2 := projectiveForAffine(x2, y2)
+		return c.affineFromProjective(c.addProjective(X1, Y1, Z1, X2, Y2, Z2))
+	}
+
 	z1 := zForAffine(x1, y1)
 	z2 := zForAffine(x2, y2)
 	return c.affineFromJacobian(c.addJacobian(x1, y1, z1, x2, y2, z2))
@@ -125,14 +375,14 @@ func (c *Curve) addJacobian(x1, y1, z1, x2, y2, z2 *big.Int) (x3, y3, z3 *big.In
 
 	P := c.P
 	z1z1 := new(big.Int).Mul(z1, z1)
-	z1z1.Mod(z1z1, P)
+	c.reduce(z1z1)
 	z2z2 := new(big.Int).Mul(z2, z2)
-	z2z2.Mod(z2z2, P)
+	c.reduce(z2z2)
 
 	u1 := new(big.Int).Mul(x1, z2z2)
-	u1.Mod(u1, P)
+	c.reduce(u1)
 	u2 := new(big.Int).Mul(x2, z1z1)
-	u2.Mod(u2, P)
+	c.reduce(u2)
 	h := new(big.Int).Sub(u2, u1)
 	if h.Sign() == -1 {
 		h.Add(h, P)
@@ -143,10 +393,10 @@ func (c *Curve) addJacobian(x1, y1, z1, x2, y2, z2 *big.Int) (x3, y3, z3 *big.In
 
 	s1 := new(big.Int).Mul(y1, z2)
 	s1.Mul(s1, z2z2)
-	s1.Mod(s1, P)
+	c.reduce(s1)
 	s2 := new(big.Int).Mul(y2, z1)
 	s2.Mul(s2, z1z1)
-	s2.Mod(s2, P)
+	c.reduce(s2)
 	r := new(big.Int).Sub(s2, s1)
 	if r.Sign() == -1 {
 		r.Add(r, P)
@@ -162,7 +412,7 @@ func (c *Curve) addJacobian(x1, y1, z1, x2, y2, z2 *big.Int) (x3, y3, z3 *big.In
 	x3.Sub(x3, j)
 	x3.Sub(x3, v)
 	x3.Sub(x3, v)
-	x3.Mod(x3, P)
+	c.reduce(x3)
 
 	y3.Set(r)
 	v.Sub(v, x3)
@@ -170,14 +420,14 @@ func (c *Curve) addJacobian(x1, y1, z1, x2, y2, z2 *big.Int) (x3, y3, z3 *big.In
 	s1.Mul(s1, j)
 	s1.Lsh(s1, 1)
 	y3.Sub(y3, s1)
-	y3.Mod(y3, P)
+	c.reduce(y3)
 
 	z3.Add(z1, z2)
 	z3.Mul(z3, z3)
 	z3.Sub(z3, z1z1)
 	z3.Sub(z3, z2z2)
 	z3.Mul(z3, h)
-	z3.Mod(z3, P)
+	c.reduce(z3)
 
 	return
 }
@@ -186,25 +436,49 @@ func (c *Curve) addJacobian(x1, y1, z1, x2, y2, z2 *big.Int) (x3, y3, z3 *big.In
 func (c *Curve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
 	panicIfNotOnCurve(c, x1, y1)
 
+	if c.Projective {
+		return c.affineFromProjective(c.doubleProjective(projectiveForAffine(x1, y1)))
+	}
+
 	z1 := zForAffine(x1, y1)
 	return c.affineFromJacobian(c.doubleJacobian(x1, y1, z1))
 }
 
+// DoubleN returns 2^k*(x,y), doubling k times before a single conversion
+// back to affine coordinates. If k <= 0 it returns a copy of (x,y).
+func (c *Curve) DoubleN(x1, y1 *big.Int, k int) (*big.Int, *big.Int) {
+	panicIfNotOnCurve(c, x1, y1)
+
+	if c.Projective {
+		x, y, z := projectiveForAffine(x1, y1)
+		for i := 0; i < k; i++ {
+			x, y, z = c.doubleProjective(x, y, z)
+		}
+		return c.affineFromProjective(x, y, z)
+	}
+
+	x, y, z := new(big.Int).Set(x1), new(big.Int).Set(y1), zForAffine(x1, y1)
+	for i := 0; i < k; i++ {
+		x, y, z = c.doubleJacobian(x, y, z)
+	}
+	return c.affineFromJacobian(x, y, z)
+}
+
 // doubleJacobian takes a Point in Jacobian coordinates, (x, y, z), and
 // returns its double, also in Jacobian form.
 func (c *Curve) doubleJacobian(x, y, z *big.Int) (x3, y3, z3 *big.Int) {
 	// See https://hyperelliptic.org/EFD/g1p/auto-shortw-jacobian.html#doubling-dbl-2007-bl
 	P := c.P
 	xx := new(big.Int).Mul(x, x)
-	xx.Mod(xx, P)
+	c.reduce(xx)
 	yy := new(big.Int).Mul(y, y)
-	yy.Mod(yy, P)
+	c.reduce(yy)
 	yyyy := new(big.Int).Mul(yy, yy)
-	yyyy.Mod(yyyy, P)
+	c.reduce(yyyy)
 	zz := new(big.Int).Mul(z, z)
-	zz.Mod(zz, P)
+	c.reduce(zz)
 	zzzz := new(big.Int).Mul(zz, zz)
-	zzzz.Mod(zzzz, P)
+	c.reduce(zzzz)
 
 	s := new(big.Int).Add(x, yy)
 	s.Mul(s, s)
@@ -217,19 +491,19 @@ func (c *Curve) doubleJacobian(x, y, z *big.Int) (x3, y3, z3 *big.Int) {
 		s.Add(s, P)
 	}
 	s.Lsh(s, 1)
-	s.Mod(s, P)
+	c.reduce(s)
 
 	m := new(big.Int).Lsh(xx, 1)
 	m.Add(m, xx)
 	m.Add(m, zzzz.Mul(c.A, zzzz))
-	m.Mod(m, P)
+	c.reduce(m)
 
 	t := new(big.Int).Mul(m, m)
 	t.Sub(t, new(big.Int).Lsh(s, 1))
 	if t.Sign() == -1 {
 		t.Add(t, P)
 	}
-	t.Mod(t, P)
+	c.reduce(t)
 
 	x3 = t
 	s.Sub(s, t)
@@ -241,7 +515,7 @@ func (c *Curve) doubleJacobian(x, y, z *big.Int) (x3, y3, z3 *big.Int) {
 	if y3.Sign() == -1 {
 		y3.Add(y3, P)
 	}
-	y3.Mod(y3, P)
+	c.reduce(y3)
 	z3 = new(big.Int).Add(y, z)
 	z3.Mul(z3, z3)
 	z3.Sub(z3, yy)
@@ -252,16 +526,47 @@ func (c *Curve) doubleJacobian(x, y, z *big.Int) (x3, y3, z3 *big.Int) {
 	if z3.Sign() == -1 {
 		z3.Add(z3, P)
 	}
-	z3.Mod(z3, P)
+	c.reduce(z3)
 
 	return
 }
 
-// ScalarMult returns k*(Bx,By).
+// AddJacobian returns the sum of P and Q given in Jacobian coordinates
+// (x, y, z), with x = x/z² and y = y/z³, so that many operations can be
+// chained before a single ToAffine. An affine Point (x, y) is (x, y, 1), and
+// z = 0 stands for the Point at infinity. The inputs are not checked to be on
+// the curve.
+func (c *Curve) AddJacobian(P, Q [3]*big.Int) [3]*big.Int {
+	x, y, z := c.addJacobian(P[0], P[1], P[2], Q[0], Q[1], Q[2])
+	return [3]*big.Int{x, y, z}
+}
+
+// DoubleJacobian returns 2P for P in Jacobian coordinates.
+func (c *Curve) DoubleJacobian(P [3]*big.Int) [3]*big.Int {
+	x, y, z := c.doubleJacobian(P[0], P[1], P[2])
+	return [3]*big.Int{x, y, z}
+}
+
+// ToAffine converts P from Jacobian to affine coordinates, returning (0, 0)
+// for the Point at infinity.
+func (c *Curve) ToAffine(P [3]*big.Int) [2]*big.Int {
+	x, y := c.affineFromJacobian(P[0], P[1], P[2])
+	return [2]*big.Int{x, y}
+}
+
+// ScalarMult returns k*(Bx,By). A negative k gives -(|k|*(Bx,By)).
 func (c *Curve) ScalarMult(Bx, By, k *big.Int) (*big.Int, *big.Int) {
 	panicIfNotOnCurve(c, Bx, By)
 
-	Bz := new(big.Int).SetInt64(1)
+	if k.Sign() < 0 {
+		return c.Neg(c.ScalarMult(Bx, By, new(big.Int).Neg(k)))
+	}
+
+	if c.Projective {
+		return c.scalarMultProjective(Bx, By, k)
+	}
+
+	Bz := zForAffine(Bx, By)
 	x, y, z := new(big.Int), new(big.Int), new(big.Int)
 	for _, b := range k.Bytes() {
 		for bitNum := 0; bitNum < 8; bitNum++ {
@@ -275,8 +580,32 @@ func (c *Curve) ScalarMult(Bx, By, k *big.Int) (*big.Int, *big.Int) {
 	return c.affineFromJacobian(x, y, z)
 }
 
-// ScalarBaseMult returns k*G, where G is the base Point of the group.
+// Multiples returns [∞, (Bx,By), 2*(Bx,By), ..., upTo*(Bx,By)], computed with
+// one addition each.
+func (c *Curve) Multiples(Bx, By *big.Int, upTo int) [][2]*big.Int {
+	panicIfNotOnCurve(c, Bx, By)
+
+	if upTo < 0 {
+		return nil
+	}
+
+	Bz := zForAffine(Bx, By)
+	points := make([][3]*big.Int, upTo+1)
+	points[0] = [3]*big.Int{new(big.Int), new(big.Int), new(big.Int)}
+	x, y, z := new(big.Int), new(big.Int), new(big.Int)
+	for i := 1; i <= upTo; i++ {
+		x, y, z = c.addJacobian(Bx, By, Bz, x, y, z)
+		points[i] = [3]*big.Int{x, y, z}
+	}
+	return c.batchAffineFromJacobian(points)
+}
+
+// ScalarBaseMult returns k*G, where G is the base Point of the group. It
+// panics if the curve has no base Point.
 func (c *Curve) ScalarBaseMult(k *big.Int) (*big.Int, *big.Int) {
+	if c.Gx == nil || c.Gy == nil {
+		panic(ErrNoBasePoint.Error())
+	}
 	return c.ScalarMult(c.Gx, c.Gy, k)
 }
 
@@ -287,8 +616,31 @@ func (c *Curve) CombinedMult(xQ, yQ, m, n *big.Int) (xP, yP *big.Int) {
 	return c.Add(x1, y1, x2, y2)
 }
 
+// CombinedMultSub calculates P=mG-nQ, where G is the generator. It negates Q
+// and shares one Shamir's-trick table between the two multiplications, so
+// it doubles once per bit of the longer scalar. As in ScalarMult, negative m
+// and n are honoured, by negating G or Q back.
+func (c *Curve) CombinedMultSub(xQ, yQ, m, n *big.Int) (xP, yP *big.Int) {
+	if IsInfinity(xQ, yQ) {
+		return c.ScalarBaseMult(m)
+	}
+	gx, gy := c.Gx, c.Gy
+	if m.Sign() < 0 {
+		gx, gy = c.Neg(gx, gy)
+	}
+	qx, qy := xQ, yQ
+	if n.Sign() >= 0 {
+		qx, qy = c.Neg(xQ, yQ)
+	}
+	t := c.newShamirTable(gx, gy, qx, qy)
+	return c.shamirMult(t, new(big.Int).Abs(m), new(big.Int).Abs(n))
+}
+
 // GenerateKey returns a public/private key pair.
 func (c *Curve) GenerateKey(rnd io.Reader) (priv, x, y *big.Int, err error) {
+	if !c.HasBasePoint() {
+		return nil, nil, nil, ErrNoBasePoint
+	}
 	nMinus1 := new(big.Int).Set(c.N)
 	nMinus1.Sub(nMinus1, big.NewInt(1))
 	for x == nil {
@@ -301,6 +653,45 @@ func (c *Curve) GenerateKey(rnd io.Reader) (priv, x, y *big.Int, err error) {
 	return
 }
 
+// GenerateKeyContext is like GenerateKey but returns ctx.Err() if ctx is done
+// before a key is generated. A read blocked in rnd is abandoned, not
+// interrupted.
+func (c *Curve) GenerateKeyContext(ctx context.Context, rnd io.Reader) (priv, x, y *big.Int, err error) {
+	type result struct {
+		k   *big.Int
+		err error
+	}
+	if !c.HasBasePoint() {
+		return nil, nil, nil, ErrNoBasePoint
+	}
+
+	nMinus1 := new(big.Int).Set(c.N)
+	nMinus1.Sub(nMinus1, big.NewInt(1))
+	for x == nil {
+		if err = ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+
+		ch := make(chan result, 1)
+		go func() {
+			k, err := rand.Int(rnd, nMinus1)
+			ch <- result{k, err}
+		}()
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, nil, ctx.Err()
+		case r := <-ch:
+			if r.err != nil {
+				return nil, nil, nil, r.err
+			}
+			priv = r.k.Add(r.k, big.NewInt(1))
+		}
+		x, y = c.ScalarBaseMult(priv)
+	}
+	return
+}
+
 // Marshal converts a Point on the curve into the uncompressed form specified in
 // SEC 1, Version 2.0, Section 2.3.3. If the Point is not on the curve (or is
 // the conventional Point at infinity), the behavior is undefined.
@@ -317,74 +708,158 @@ func (c *Curve) Marshal(x, y *big.Int) []byte {
 }
 
 // MarshalCompressed converts a Point on the curve into the compressed form
-// specified in SEC 1, Version 2.0, Section 2.3.3. If the Point is not on the
-// curve (or is the conventional Point at infinity), the behavior is undefined.
+// specified in SEC 1, Version 2.0, Section 2.3.3. The conventional Point at
+// infinity is encoded as the single byte 0x00. If the Point is not on the
+// curve, the behavior is undefined.
 func (c *Curve) MarshalCompressed(x, y *big.Int) []byte {
+	if IsInfinity(x, y) {
+		return []byte{0}
+	}
 	byteLen := (c.BitSize + 7) / 8
 	compressed := make([]byte, 1+byteLen)
-	compressed[0] = byte(y.Bit(0)) | 2
+	compressed[0] = c.ParityByte(y)
 	x.FillBytes(compressed[1:])
 	return compressed
 }
 
+// ParityByte returns the prefix byte of the compressed form of a Point with
+// the given y, 0x02 if y is even and 0x03 if it is odd.
+func (c *Curve) ParityByte(y *big.Int) byte {
+	return byte(y.Bit(0)) | 2
+}
+
+// IsEvenY reports whether y is even, as it is for the x-only public keys of
+// BIP-340. y must be reduced modulo P.
+func (c *Curve) IsEvenY(y *big.Int) bool {
+	return y.Bit(0) == 0
+}
+
 // Unmarshal converts a Point, serialized by Marshal, into an x, y pair. It is
 // an error if the Point is not in uncompressed form, is not on the curve, or is
 // the Point at infinity. On error, x = nil.
 func (c *Curve) Unmarshal(data []byte) (x, y *big.Int) {
+	x, y, err := c.unmarshal(data)
+	if err != nil {
+		return nil, nil
+	}
+	return
+}
+
+// unmarshal is Unmarshal with the reason for a failure: ErrPointEncoding if
+// data is not in uncompressed form, and ErrNotOnCurve if the Point is not on
+// the curve.
+func (c *Curve) unmarshal(data []byte) (x, y *big.Int, err error) {
 	byteLen := (c.BitSize + 7) / 8
 	if len(data) != 1+2*byteLen {
-		return nil, nil
+		return nil, nil, ErrPointEncoding
 	}
 	if data[0] != 4 { // uncompressed form
-		return nil, nil
+		return nil, nil, ErrPointEncoding
 	}
 	p := c.P
 	x = new(big.Int).SetBytes(data[1 : 1+byteLen])
 	y = new(big.Int).SetBytes(data[1+byteLen:])
 	if x.Cmp(p) >= 0 || y.Cmp(p) >= 0 {
-		return nil, nil
+		return nil, nil, ErrPointEncoding
 	}
 	if !c.IsOnCurve(x, y) {
-		return nil, nil
+		return nil, nil, ErrNotOnCurve
 	}
 	return
 }
 
 // UnmarshalCompressed converts a Point, serialized by MarshalCompressed, into
-// an x, y pair. It is an error if the Point is not in compressed form, is not
-// on the curve, or is the Point at infinity. On error, x = nil.
+// an x, y pair. The single byte 0x00 is the Point at infinity, (0, 0), which
+// callers expecting a public key must reject. It is an error if the Point is
+// not in compressed form or is not on the curve, or the parity byte is odd
+// for a Point with y = 0, which UnmarshalCompressedLenient accepts. On error,
+// x = nil.
 func (c *Curve) UnmarshalCompressed(data []byte) (x, y *big.Int) {
+	x, y, flipped, err := c.UnmarshalCompressedLenient(data)
+	if err != nil || flipped {
+		return nil, nil
+	}
+	return
+}
+
+// UnmarshalCompressedLenient is like UnmarshalCompressed, but if no Point
+// has the parity of y given by the prefix byte while the other root exists,
+// it returns that root with flippedParity set instead of failing. Since the
+// two roots are y and P - y, of opposite parity unless y = 0, this only
+// happens for Points of order two with an odd prefix. It returns
+// ErrPointEncoding if data is not in compressed form, and ErrNotOnCurve if x
+// is not the x-coordinate of a Point.
+func (c *Curve) UnmarshalCompressedLenient(data []byte) (x, y *big.Int, flippedParity bool, err error) {
+	if len(data) == 1 && data[0] == 0 {
+		return new(big.Int), new(big.Int), false, nil
+	}
 	byteLen := (c.BitSize + 7) / 8
 	if len(data) != 1+byteLen {
-		return nil, nil
+		return nil, nil, false, ErrPointEncoding
 	}
 	if data[0] != 2 && data[0] != 3 { // compressed form
-		return nil, nil
+		return nil, nil, false, ErrPointEncoding
 	}
-	p := c.P
 	x = new(big.Int).SetBytes(data[1:])
-	if x.Cmp(p) >= 0 {
-		return nil, nil
+	if x.Cmp(c.P) >= 0 {
+		return nil, nil, false, ErrPointEncoding
 	}
-	// y² = x³ + ax + b
-	y = c.evaluatePolynomial(x)
-	y = y.ModSqrt(y, p)
-	if y == nil {
-		return nil, nil
+	y, ny, ok := c.LiftX(x)
+	if !ok {
+		return nil, nil, false, ErrNotOnCurve
 	}
 	if byte(y.Bit(0)) != data[0]&1 {
-		y.Neg(y).Mod(y, p)
+		y = ny
 	}
-	if !c.IsOnCurve(x, y) {
-		return nil, nil
+	return x, y, byte(y.Bit(0)) != data[0]&1, nil
+}
+
+// LiftXEven converts an x-only public key of BIP-340, the x-coordinate
+// encoded in (BitSize+7)/8 big-endian bytes, into the Point with that x and
+// an even y. Unlike UnmarshalCompressed, there is no prefix byte: the parity
+// of y is implicitly even. It is an error if xBytes has the wrong length, or
+// x is not less than P or not the x-coordinate of a Point.
+func (c *Curve) LiftXEven(xBytes []byte) (x, y *big.Int, err error) {
+	if len(xBytes) != (c.BitSize+7)/8 {
+		return nil, nil, ErrInvalidPublicKey
 	}
-	return
+	x = new(big.Int).SetBytes(xBytes)
+	y, ny, ok := c.LiftX(x)
+	if !ok {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	if !c.IsEvenY(y) {
+		y = ny
+	}
+	return x, y, nil
+}
+
+// LiftX returns both y-coordinates of the points with the given x-coordinate,
+// the square roots of x³ + ax + b, with y2 = P - y1. If there is no such
+// Point, ok is false.
+func (c *Curve) LiftX(x *big.Int) (y1, y2 *big.Int, ok bool) {
+	p := c.P
+	if x.Sign() < 0 || x.Cmp(p) >= 0 {
+		return nil, nil, false
+	}
+	// y² = x³ + ax + b
+	y1 = modSqrt(c.evaluatePolynomial(x), p)
+	if y1 == nil {
+		return nil, nil, false
+	}
+	y2 = new(big.Int).Sub(p, y1)
+	y2.Mod(y2, p)
+	return y1, y2, true
 }
 
 func panicIfNotOnCurve(curve *Curve, x, y *big.Int) {
+	if curve.SkipValidation {
+		return
+	}
+
 	// (0, 0) is the Point at infinity by convention. It's ok to operate on it,
 	// although IsOnCurve is documented to return false for it.
-	if x.Sign() == 0 && y.Sign() == 0 {
+	if IsInfinity(x, y) {
 		return
 	}
 
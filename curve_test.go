@@ -1,9 +1,13 @@
 package ecc
 
 import (
+	"context"
+	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/hex"
 	"math/big"
 	"testing"
+	"time"
 )
 
 func sampleCurves() map[string]*Curve {
@@ -31,6 +35,18 @@ func sampleCurves() map[string]*Curve {
 		BitSize: 8,
 	}
 
+	// #E = 4·2521
+	curves["COFACTOR"] = &Curve{
+		P:       big.NewInt(10007),
+		A:       big.NewInt(2),
+		B:       big.NewInt(20),
+		Gx:      big.NewInt(7345),
+		Gy:      big.NewInt(5479),
+		N:       big.NewInt(2521),
+		H:       big.NewInt(4),
+		BitSize: 14,
+	}
+
 	curves["S256"] = &Curve{
 		P: BigFromDecimal("11579208923731619542357098500868790785326998466564" +
 			"0564039457584007908834671663"),
@@ -64,6 +80,21 @@ func sampleCurves() map[string]*Curve {
 	return curves
 }
 
+// p256 returns NIST P-256, which is not among sampleCurves.
+func p256() *Curve {
+	return &Curve{
+		P:       BigFromHex("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),
+		A:       BigFromDecimal("-3"),
+		B:       BigFromHex("5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),
+		Gx:      BigFromHex("6b17d1f2e12c4247f8bce6e563a440f277037d812deb33a0f4a13945d898c296"),
+		Gy:      BigFromHex("4fe342e2fe1a7f9b8ee7eb4a7c0f9e162bce33576b315ececbb6406837bf51f5"),
+		N:       BigFromHex("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551"),
+		H:       BigFromDecimal("1"),
+		BitSize: 256,
+		Name:    "P-256",
+	}
+}
+
 func testAllCurves(t *testing.T, f func(*testing.T, *Curve)) {
 	for name, c := range sampleCurves() {
 		c := c
@@ -142,11 +173,19 @@ func TestInfinity(t *testing.T) {
 		if xx, yy := curve.Unmarshal(curve.Marshal(x, y)); xx != nil || yy != nil {
 			t.Errorf("Unmarshal(Marshal(∞)) did not return an error")
 		}
-		// We don't test UnmarshalCompressed(MarshalCompressed(∞)) because there are
-		// two valid points with x = 0.
 		if xx, yy := curve.Unmarshal([]byte{0x00}); xx != nil || yy != nil {
 			t.Errorf("Unmarshal(∞) did not return an error")
 		}
+
+		if b := curve.MarshalCompressed(x, y); len(b) != 1 || b[0] != 0 {
+			t.Errorf("MarshalCompressed(∞): got: %x, want: 00", b)
+		}
+		if xx, yy := curve.UnmarshalCompressed(curve.MarshalCompressed(x, y)); xx == nil || !IsInfinity(xx, yy) {
+			t.Errorf("UnmarshalCompressed(MarshalCompressed(∞)): got: (%d,%d), want: (0,0)", xx, yy)
+		}
+		if _, err := curve.VerifyCompressed([]byte{0x00}, []byte("testing"), big.NewInt(1), big.NewInt(1)); err != ErrInvalidPublicKey {
+			t.Errorf("VerifyCompressed(∞): got: %v, want: %v", err, ErrInvalidPublicKey)
+		}
 	})
 }
 
@@ -162,6 +201,34 @@ func TestKeyGeneration(t *testing.T) {
 	})
 }
 
+type slowReader struct {
+	delay time.Duration
+}
+
+func (r slowReader) Read(b []byte) (int, error) {
+	time.Sleep(r.delay)
+	return rand.Read(b)
+}
+
+func TestGenerateKeyContext(t *testing.T) {
+	curve := sampleCurves()["S256"]
+
+	_, x, y, err := curve.GenerateKeyContext(context.Background(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		t.Errorf("public key invalid")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, _, err = curve.GenerateKeyContext(ctx, slowReader{time.Second})
+	if err != context.DeadlineExceeded {
+		t.Errorf("got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+}
+
 // TestInvalidCoordinates tests big.Int values that are not valid field elements
 // (negative or bigger than P). They are expected to return false from
 // IsOnCurve, all other behavior is undefined.
@@ -247,3 +314,785 @@ func BenchmarkScalarMult(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkScalarMultSkipValidation(b *testing.B) {
+	benchmarkAllCurves(b, func(b *testing.B, curve *Curve) {
+		_, x, y, _ := curve.GenerateKey(rand.Reader)
+		priv, _, _, _ := curve.GenerateKey(rand.Reader)
+		curve.SkipValidation = true
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			x, y = curve.ScalarMult(x, y, priv)
+		}
+	})
+}
+
+func TestDoubleN(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		_, x, y, _ := curve.GenerateKey(rand.Reader)
+		for _, projective := range []bool{false, true} {
+			curve.Projective = projective
+			for _, k := range []int{0, 1, 2, 5, 64, 300} {
+				want := new(big.Int).Lsh(big.NewInt(1), uint(k))
+				wx, wy := curve.ScalarMult(x, y, want)
+				if gx, gy := curve.DoubleN(x, y, k); gx.Cmp(wx) != 0 || gy.Cmp(wy) != 0 {
+					t.Errorf("projective %v, k = %d: got: (%d,%d), want: (%d,%d)", projective, k, gx, gy, wx, wy)
+				}
+			}
+			if gx, gy := curve.DoubleN(new(big.Int), new(big.Int), 3); !IsInfinity(gx, gy) {
+				t.Errorf("projective %v: 8·∞ = (%d,%d)", projective, gx, gy)
+			}
+		}
+	})
+}
+
+func BenchmarkDoubleN(b *testing.B) {
+	const k = 64
+	benchmarkAllCurves(b, func(b *testing.B, curve *Curve) {
+		_, x, y, _ := curve.GenerateKey(rand.Reader)
+		b.Run("DoubleN", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				curve.DoubleN(x, y, k)
+			}
+		})
+		b.Run("Double", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				dx, dy := x, y
+				for j := 0; j < k; j++ {
+					dx, dy = curve.Double(dx, dy)
+				}
+			}
+		})
+	})
+}
+
+func TestSkipValidation(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		k, _, _, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantX, wantY := curve.ScalarBaseMult(k)
+		dx, dy := curve.Double(curve.Gx, curve.Gy)
+		sx, sy := curve.Add(curve.Gx, curve.Gy, curve.Gx, curve.Gy)
+
+		fast := *curve
+		fast.SkipValidation = true
+		if x, y := fast.ScalarMult(curve.Gx, curve.Gy, k); x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+			t.Errorf("ScalarMult: got: (%d, %d), want: (%d, %d)", x, y, wantX, wantY)
+		}
+		if x, y := fast.Double(curve.Gx, curve.Gy); x.Cmp(dx) != 0 || y.Cmp(dy) != 0 {
+			t.Errorf("Double: got: (%d, %d), want: (%d, %d)", x, y, dx, dy)
+		}
+		if x, y := fast.Add(curve.Gx, curve.Gy, curve.Gx, curve.Gy); x.Cmp(sx) != 0 || y.Cmp(sy) != 0 {
+			t.Errorf("Add: got: (%d, %d), want: (%d, %d)", x, y, sx, sy)
+		}
+
+		// an off-curve point no longer panics
+		fast.ScalarMult(curve.Gx, new(big.Int).Add(curve.Gy, big.NewInt(1)), k)
+	})
+}
+
+func TestUnmarshalCompressed(t *testing.T) {
+	// P = 29 = 1 mod 4, where the (p+1)/4 shortcut does not apply
+	curve := sampleCurves()["TOY"]
+	p := curve.P
+	found := 0
+	for x := big.NewInt(0); x.Cmp(p) < 0; x.Add(x, big.NewInt(1)) {
+		y := new(big.Int).ModSqrt(curve.evaluatePolynomial(x), p)
+		if y == nil || y.Sign() == 0 {
+			continue
+		}
+		ny := new(big.Int).Sub(p, y)
+		for _, y := range []*big.Int{y, ny} {
+			found++
+			x1, y1 := curve.UnmarshalCompressed(curve.MarshalCompressed(x, y))
+			if x1 == nil || x1.Cmp(x) != 0 || y1.Cmp(y) != 0 {
+				t.Errorf("got: (%d,%d), want: (%d,%d)", x1, y1, x, y)
+			}
+		}
+	}
+	if found == 0 {
+		t.Fatal("no points found")
+	}
+}
+
+func TestParityByte(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		seen := map[bool]bool{}
+		for k := int64(1); k <= 10; k++ {
+			x, y := curve.ScalarBaseMult(big.NewInt(k))
+			// y and P - y have opposite parities
+			for _, y := range []*big.Int{y, new(big.Int).Sub(curve.P, y)} {
+				even := new(big.Int).Mod(y, big.NewInt(2)).Sign() == 0
+				seen[even] = true
+				if got := curve.IsEvenY(y); got != even {
+					t.Errorf("IsEvenY(%d): got: %v, want: %v", y, got, even)
+				}
+				want := byte(3)
+				if even {
+					want = 2
+				}
+				if got := curve.ParityByte(y); got != want {
+					t.Errorf("ParityByte(%d): got: %#x, want: %#x", y, got, want)
+				}
+				if b := curve.MarshalCompressed(x, y); b[0] != want {
+					t.Errorf("MarshalCompressed(%d, %d)[0]: got: %#x, want: %#x", x, y, b[0], want)
+				}
+			}
+		}
+		if !seen[true] || !seen[false] {
+			t.Errorf("only saw even = %v", seen)
+		}
+	})
+}
+
+func TestUnmarshalCompressedLenient(t *testing.T) {
+	// #E = 80, with (57, 0) of order two
+	curve := &Curve{P: big.NewInt(97), A: big.NewInt(46), B: big.NewInt(74), BitSize: 7}
+
+	// y = 0 is even, so the prefix 03 is wrong
+	if x, _ := curve.UnmarshalCompressed([]byte{3, 57}); x != nil {
+		t.Errorf("strict: got: %d, want: nil", x)
+	}
+	x, y, flipped, err := curve.UnmarshalCompressedLenient([]byte{3, 57})
+	if err != nil || !flipped || x.Int64() != 57 || y.Sign() != 0 {
+		t.Errorf("lenient: got: (%d,%d), %v, %v, want: (57,0), true, nil", x, y, flipped, err)
+	}
+	x, y, flipped, err = curve.UnmarshalCompressedLenient([]byte{2, 57})
+	if err != nil || flipped || x.Int64() != 57 || y.Sign() != 0 {
+		t.Errorf("lenient: got: (%d,%d), %v, %v, want: (57,0), false, nil", x, y, flipped, err)
+	}
+
+	// with y ≠ 0 both parities exist, so the prefix picks one without a flip
+	gx, gy := big.NewInt(49), big.NewInt(45)
+	for _, prefix := range []byte{2, 3} {
+		x, y, flipped, err := curve.UnmarshalCompressedLenient([]byte{prefix, 49})
+		if err != nil || flipped || x.Cmp(gx) != 0 || byte(y.Bit(0)) != prefix&1 || !curve.IsOnCurve(x, y) {
+			t.Errorf("%02x: got: (%d,%d), %v, %v", prefix, x, y, flipped, err)
+		}
+		if y.Cmp(gy) != 0 && y.Cmp(new(big.Int).Sub(curve.P, gy)) != 0 {
+			t.Errorf("%02x: got: y = %d, want: ±%d", prefix, y, gy)
+		}
+	}
+
+	noRoot := int64(-1)
+	for x := int64(0); x < 97 && noRoot < 0; x++ {
+		if _, _, ok := curve.LiftX(big.NewInt(x)); !ok {
+			noRoot = x
+		}
+	}
+	for _, c := range []struct {
+		data []byte
+		want error
+	}{
+		{[]byte{2, byte(noRoot)}, ErrNotOnCurve},
+		{[]byte{2, 97}, ErrPointEncoding},
+		{[]byte{4, 57}, ErrPointEncoding},
+		{[]byte{2, 0, 57}, ErrPointEncoding},
+	} {
+		if _, _, _, err := curve.UnmarshalCompressedLenient(c.data); err != c.want {
+			t.Errorf("%x: got: %v, want: %v", c.data, err, c.want)
+		}
+	}
+}
+
+func TestJInvariant(t *testing.T) {
+	cases := []struct {
+		p, a, b, want *big.Int
+	}{
+		{big.NewInt(29), big.NewInt(0), big.NewInt(7), big.NewInt(0)},
+		{big.NewInt(29), big.NewInt(3), big.NewInt(0), big.NewInt(1728 % 29)},
+		// 1728·4·2³/(4·2³+27·3²) mod 97
+		{big.NewInt(97), big.NewInt(2), big.NewInt(3), big.NewInt(36)},
+	}
+	for _, c := range cases {
+		curve := &Curve{P: c.p, A: c.a, B: c.b}
+		j, err := curve.JInvariant()
+		if err != nil {
+			t.Errorf("got error: %v", err)
+			continue
+		}
+		if j.Cmp(c.want) != 0 {
+			t.Errorf("got: %d, want: %d", j, c.want)
+		}
+	}
+
+	j, err := sampleCurves()["S256"].JInvariant()
+	if err != nil || j.Sign() != 0 {
+		t.Errorf("S256: got: %d, %v, want: 0", j, err)
+	}
+
+	singular := &Curve{P: big.NewInt(29), A: big.NewInt(0), B: big.NewInt(0)}
+	if _, err := singular.JInvariant(); err != ErrSingularCurve {
+		t.Errorf("got: %v, want: %v", err, ErrSingularCurve)
+	}
+}
+
+func TestDiscriminant(t *testing.T) {
+	// -16·(4·2³+27·3²) = -4400 ≡ 62 mod 97
+	curve := &Curve{P: big.NewInt(97), A: big.NewInt(2), B: big.NewInt(3)}
+	if d := curve.Discriminant(); d.Int64() != 62 {
+		t.Errorf("got: %d, want: 62", d)
+	}
+
+	// secp256k1: -16·27·7²
+	s := sampleCurves()["S256"]
+	want := new(big.Int).Sub(s.P, big.NewInt(16*27*49))
+	if d := s.Discriminant(); d.Cmp(want) != 0 {
+		t.Errorf("S256: got: %d, want: %d", d, want)
+	}
+
+	// x³ - 3x + 2 = (x - 1)²(x + 2)
+	singular := &Curve{P: big.NewInt(97), A: big.NewInt(94), B: big.NewInt(2)}
+	if d := singular.Discriminant(); d.Sign() != 0 {
+		t.Errorf("singular: got: %d, want: 0", d)
+	}
+	if singular.IsNonsingular() {
+		t.Errorf("singular: IsNonsingular is true")
+	}
+}
+
+func TestCofactor(t *testing.T) {
+	curve := sampleCurves()["COFACTOR"]
+
+	x, y := curve.ScalarBaseMult(curve.N)
+	if x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("N·G != ∞")
+	}
+
+	for i := 0; i < 10; i++ {
+		_, x, y, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x, y := curve.ScalarMult(x, y, curve.N); x.Sign() != 0 || y.Sign() != 0 {
+			t.Errorf("public key is not in the prime order subgroup")
+		}
+	}
+
+	// (940, 9937) has order 4
+	x, y = big.NewInt(940), big.NewInt(9937)
+	if !curve.IsOnCurve(x, y) {
+		t.Fatal("(940, 9937) is not on the curve")
+	}
+	if x, y := curve.ScalarMult(x, y, curve.H); x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("H·(940, 9937) != ∞")
+	}
+	priv, _, _, _ := curve.GenerateKey(rand.Reader)
+	if _, err := curve.ECDH(priv, x, y); err != ErrSmallOrder {
+		t.Errorf("got: %v, want: %v", err, ErrSmallOrder)
+	}
+}
+
+func TestMultiples(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		_, x, y, _ := curve.GenerateKey(rand.Reader)
+		upTo := 50
+		if curve.N.IsInt64() && curve.N.Int64() < 50 {
+			// run past N to cover ∞ and the doubling case
+			upTo = int(curve.N.Int64()) + 5
+		}
+		multiples := curve.Multiples(x, y, upTo)
+		if len(multiples) != upTo+1 {
+			t.Fatalf("got %d multiples, want %d", len(multiples), upTo+1)
+		}
+		for i, m := range multiples {
+			wx, wy := curve.ScalarMult(x, y, big.NewInt(int64(i)))
+			if m[0].Cmp(wx) != 0 || m[1].Cmp(wy) != 0 {
+				t.Errorf("%d·P: got: (%d,%d), want: (%d,%d)", i, m[0], m[1], wx, wy)
+			}
+		}
+	})
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	curves := sampleCurves()
+	for _, curve := range curves {
+		_, x, y, _ := curve.GenerateKey(rand.Reader)
+		f.Add(curve.Marshal(x, y))
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0x04})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for name, curve := range curves {
+			x, y := curve.Unmarshal(data)
+			if x != nil && !curve.IsOnCurve(x, y) {
+				t.Errorf("%s: Unmarshal returned (%d,%d) not on the curve", name, x, y)
+			}
+		}
+	})
+}
+
+func FuzzUnmarshalCompressed(f *testing.F) {
+	curves := sampleCurves()
+	for _, curve := range curves {
+		_, x, y, _ := curve.GenerateKey(rand.Reader)
+		f.Add(curve.MarshalCompressed(x, y))
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0x02})
+	f.Add([]byte{0x03, 0x00})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for name, curve := range curves {
+			x, y := curve.UnmarshalCompressed(data)
+			if x != nil && !IsInfinity(x, y) && !curve.IsOnCurve(x, y) {
+				t.Errorf("%s: UnmarshalCompressed returned (%d,%d) not on the curve", name, x, y)
+			}
+		}
+	})
+}
+
+func TestLiftX(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		y1, y2, ok := curve.LiftX(curve.Gx)
+		if !ok {
+			t.Fatal("no points with x = Gx")
+		}
+		if !curve.IsOnCurve(curve.Gx, y1) || !curve.IsOnCurve(curve.Gx, y2) {
+			t.Errorf("lifted points are not on the curve")
+		}
+		if y1.Cmp(curve.Gy) != 0 && y2.Cmp(curve.Gy) != 0 {
+			t.Errorf("got: %d, %d, want: %d", y1, y2, curve.Gy)
+		}
+		nx, ny := curve.Neg(curve.Gx, y1)
+		if nx.Cmp(curve.Gx) != 0 || ny.Cmp(y2) != 0 {
+			t.Errorf("(x, y2) is not the negation of (x, y1)")
+		}
+	})
+
+	// x³ + 4x + 20 = 14 is not a square mod 29
+	if _, _, ok := sampleCurves()["TOY"].LiftX(big.NewInt(7)); ok {
+		t.Errorf("LiftX(7) succeeded")
+	}
+}
+
+func TestScalarFromBytes(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		k, _, _, _ := curve.GenerateKey(rand.Reader)
+		// k + N reduces to k
+		kn := new(big.Int).Add(k, curve.N)
+		be := kn.Bytes()
+		le := make([]byte, len(be))
+		for i := range be {
+			le[len(be)-1-i] = be[i]
+		}
+
+		k1, err := curve.ScalarFromBytes(be, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k2, err := curve.ScalarFromBytes(le, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k1.Cmp(k) != 0 || k2.Cmp(k) != 0 {
+			t.Errorf("got: %d, %d, want: %d", k1, k2, k)
+		}
+
+		for _, b := range [][]byte{nil, curve.N.Bytes(), make([]byte, 2*curve.OrderBytes()+1)} {
+			if _, err := curve.ScalarFromBytes(b, false); err != ErrInvalidScalar {
+				t.Errorf("%x: got: %v, want: %v", b, err, ErrInvalidScalar)
+			}
+		}
+	})
+}
+
+func TestNegChecked(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		x, y, err := curve.NegChecked(curve.Gx, curve.Gy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sx, sy := curve.Add(curve.Gx, curve.Gy, x, y); sx.Sign() != 0 || sy.Sign() != 0 {
+			t.Errorf("G + (-G): got: (%d, %d), want: (0, 0)", sx, sy)
+		}
+
+		x, y, err = curve.NegChecked(new(big.Int), new(big.Int))
+		if err != nil || x.Sign() != 0 || y.Sign() != 0 {
+			t.Errorf("-∞: got: (%v, %v, %v), want: (0, 0, nil)", x, y, err)
+		}
+		x, y = curve.Neg(new(big.Int), new(big.Int))
+		if x.Sign() != 0 || y.Sign() != 0 {
+			t.Errorf("Neg(∞): got: (%d, %d), want: (0, 0)", x, y)
+		}
+
+		if _, _, err = curve.NegChecked(curve.Gx, new(big.Int).Add(curve.Gy, big.NewInt(1))); err != ErrNotOnCurve {
+			t.Errorf("got: %v, want: %v", err, ErrNotOnCurve)
+		}
+	})
+}
+
+func TestAreNegatives(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		inf := new(big.Int)
+		_, x, y, _ := curve.GenerateKey(rand.Reader)
+		nx, ny := curve.Neg(x, y)
+		dx, dy := curve.Double(x, y)
+		for _, c := range []struct {
+			x1, y1, x2, y2 *big.Int
+			ans            bool
+		}{
+			{x, y, nx, ny, true},
+			{nx, ny, x, y, true},
+			{inf, inf, inf, inf, true},
+			{x, y, x, y, false},
+			{x, y, dx, dy, false},
+			{x, y, inf, inf, false},
+			{inf, inf, nx, ny, false},
+		} {
+			if got := curve.AreNegatives(c.x1, c.y1, c.x2, c.y2); got != c.ans {
+				t.Errorf("(%d,%d), (%d,%d): got: %v, want: %v", c.x1, c.y1, c.x2, c.y2, got, c.ans)
+			}
+		}
+	})
+
+	// (57, 0) has order 2 on y² = x³ + 46x + 74 over F_97
+	curve := &Curve{P: big.NewInt(97), A: big.NewInt(46), B: big.NewInt(74)}
+	if !curve.AreNegatives(big.NewInt(57), big.NewInt(0), big.NewInt(57), big.NewInt(0)) {
+		t.Errorf("(57,0): got: false, want: true")
+	}
+}
+
+func TestReduce(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		for _, m := range []struct {
+			name   string
+			mod    *big.Int
+			reduce func(*big.Int) *big.Int
+		}{
+			{"ReduceField", curve.P, curve.ReduceField},
+			{"ReduceScalar", curve.N, curve.ReduceScalar},
+		} {
+			big2 := new(big.Int).Lsh(m.mod, 3)
+			cases := []struct {
+				in, ans *big.Int
+			}{
+				{big.NewInt(0), big.NewInt(0)},
+				{big.NewInt(-1), new(big.Int).Sub(m.mod, big.NewInt(1))},
+				{new(big.Int).Neg(m.mod), big.NewInt(0)},
+				{new(big.Int).Set(m.mod), big.NewInt(0)},
+				{new(big.Int).Add(big2, big.NewInt(5)), new(big.Int).Mod(big.NewInt(5), m.mod)},
+				{new(big.Int).Sub(big.NewInt(-2), big2), new(big.Int).Sub(m.mod, big.NewInt(2))},
+			}
+			for _, c := range cases {
+				in := new(big.Int).Set(c.in)
+				got := m.reduce(c.in)
+				if got.Cmp(c.ans) != 0 {
+					t.Errorf("%s(%d): got: %d, want: %d", m.name, c.in, got, c.ans)
+				}
+				if c.in.Cmp(in) != 0 {
+					t.Errorf("%s modified its input", m.name)
+				}
+			}
+		}
+	})
+}
+
+func TestNegateScalar(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		N := curve.N
+		one := big.NewInt(1)
+		for _, c := range []struct {
+			in, ans *big.Int
+		}{
+			{big.NewInt(0), big.NewInt(0)},
+			{big.NewInt(1), new(big.Int).Sub(N, one)},
+			{new(big.Int).Sub(N, one), big.NewInt(1)},
+			{new(big.Int).Set(N), big.NewInt(0)},
+			{new(big.Int).Add(N, one), new(big.Int).Sub(N, one)},
+			{new(big.Int).Lsh(N, 3), big.NewInt(0)},
+			{big.NewInt(-1), big.NewInt(1)},
+		} {
+			in := new(big.Int).Set(c.in)
+			got := curve.NegateScalar(c.in)
+			if got.Cmp(c.ans) != 0 {
+				t.Errorf("NegateScalar(%d): got: %d, want: %d", c.in, got, c.ans)
+			}
+			if c.in.Cmp(in) != 0 {
+				t.Errorf("NegateScalar modified its input")
+			}
+		}
+
+		k, _ := rand.Int(rand.Reader, N)
+		x, y := curve.ScalarBaseMult(k)
+		nx, ny := curve.ScalarBaseMult(curve.NegateScalar(k))
+		if wx, wy := curve.Neg(x, y); nx.Cmp(wx) != 0 || ny.Cmp(wy) != 0 {
+			t.Errorf("(-k)G: got: (%d,%d), want: (%d,%d)", nx, ny, wx, wy)
+		}
+	})
+}
+
+func TestNewCurve(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		c, err := NewCurve(curve.P, curve.A, curve.B, curve.Gx, curve.Gy, curve.N, curve.H, curve.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.BitSize != curve.P.BitLen() {
+			t.Errorf("BitSize: got: %d, want: %d", c.BitSize, curve.P.BitLen())
+		}
+		priv, x, y, err := c.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if px, py := curve.ScalarBaseMult(priv); px.Cmp(x) != 0 || py.Cmp(y) != 0 {
+			t.Errorf("got: (%d, %d), want: (%d, %d)", x, y, px, py)
+		}
+
+		gy := new(big.Int).Add(curve.Gy, big.NewInt(1))
+		if _, err := NewCurve(curve.P, curve.A, curve.B, curve.Gx, gy, curve.N, curve.H, curve.Name); err != ErrNotOnCurve {
+			t.Errorf("off-curve G: got: %v, want: %v", err, ErrNotOnCurve)
+		}
+		n := new(big.Int).Add(curve.N, big.NewInt(1))
+		if _, err := NewCurve(curve.P, curve.A, curve.B, curve.Gx, curve.Gy, n, curve.H, curve.Name); err != ErrInvalidOrder {
+			t.Errorf("wrong N: got: %v, want: %v", err, ErrInvalidOrder)
+		}
+
+		for i, want := range []error{
+			ErrMissingParam, ErrMissingParam, ErrMissingParam,
+			ErrNoBasePoint, ErrNoBasePoint, ErrInvalidOrder,
+		} {
+			args := []*big.Int{curve.P, curve.A, curve.B, curve.Gx, curve.Gy, curve.N}
+			args[i] = nil
+			if _, err := NewCurve(args[0], args[1], args[2], args[3], args[4], args[5], curve.H, curve.Name); err != want {
+				t.Errorf("nil argument %d: got: %v, want: %v", i, err, want)
+			}
+		}
+	})
+
+	// 4a³ + 27b² = 4·(-3)³ + 27·2² = 0
+	_, err := NewCurve(big.NewInt(29), big.NewInt(-3), big.NewInt(2), big.NewInt(1), big.NewInt(0), big.NewInt(2), nil, "")
+	if err != ErrSingularCurve {
+		t.Errorf("singular: got: %v, want: %v", err, ErrSingularCurve)
+	}
+}
+
+func TestScalarMultOracle(t *testing.T) {
+	// k·P against P + P + ... + P, and -k·P against its negation
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		_, px, py, _ := curve.GenerateKey(rand.Reader)
+		sx, sy := new(big.Int), new(big.Int)
+		for k := int64(0); k <= 70; k++ {
+			if x, y := curve.ScalarMult(px, py, big.NewInt(k)); x.Cmp(sx) != 0 || y.Cmp(sy) != 0 {
+				t.Fatalf("%d·(%d, %d): got: (%d, %d), want: (%d, %d)", k, px, py, x, y, sx, sy)
+			}
+			nx, ny := curve.Neg(sx, sy)
+			if x, y := curve.ScalarMult(px, py, big.NewInt(-k)); x.Cmp(nx) != 0 || y.Cmp(ny) != 0 {
+				t.Fatalf("%d·(%d, %d): got: (%d, %d), want: (%d, %d)", -k, px, py, x, y, nx, ny)
+			}
+			sx, sy = curve.Add(sx, sy, px, py)
+		}
+		if x, y := curve.ScalarMult(new(big.Int), new(big.Int), big.NewInt(5)); !IsInfinity(x, y) {
+			t.Errorf("5·∞: got: (%d, %d), want: (0, 0)", x, y)
+		}
+	})
+
+	// k·P against crypto/elliptic
+	for name, c := range map[string]struct {
+		curve *Curve
+		std   elliptic.Curve
+	}{
+		"P-256": {p256(), elliptic.P256()},
+		"P-384": {sampleCurves()["P384"], elliptic.P384()},
+	} {
+		for i := 0; i < 10; i++ {
+			_, px, py, _ := c.curve.GenerateKey(rand.Reader)
+			k, _ := rand.Int(rand.Reader, c.curve.N)
+			x, y := c.curve.ScalarMult(px, py, k)
+			wx, wy := c.std.ScalarMult(px, py, k.Bytes())
+			if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+				t.Errorf("%s: %d·(%d, %d): got: (%d, %d), want: (%d, %d)", name, k, px, py, x, y, wx, wy)
+			}
+		}
+	}
+}
+
+func TestJacobianAPI(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		G := [3]*big.Int{curve.Gx, curve.Gy, big.NewInt(1)}
+
+		// 2G + G, doubled twice, plus G: 13G
+		P := curve.AddJacobian(curve.DoubleJacobian(G), G)
+		P = curve.DoubleJacobian(curve.DoubleJacobian(P))
+		P = curve.AddJacobian(P, G)
+		got := curve.ToAffine(P)
+
+		wx, wy := curve.Double(curve.Gx, curve.Gy)
+		wx, wy = curve.Add(wx, wy, curve.Gx, curve.Gy)
+		wx, wy = curve.Double(wx, wy)
+		wx, wy = curve.Double(wx, wy)
+		wx, wy = curve.Add(wx, wy, curve.Gx, curve.Gy)
+		if got[0].Cmp(wx) != 0 || got[1].Cmp(wy) != 0 {
+			t.Errorf("got: (%d, %d), want: (%d, %d)", got[0], got[1], wx, wy)
+		}
+		if sx, sy := curve.ScalarBaseMult(big.NewInt(13)); got[0].Cmp(sx) != 0 || got[1].Cmp(sy) != 0 {
+			t.Errorf("got: (%d, %d), want 13G: (%d, %d)", got[0], got[1], sx, sy)
+		}
+
+		inf := [3]*big.Int{new(big.Int), new(big.Int), new(big.Int)}
+		if got := curve.ToAffine(curve.AddJacobian(inf, G)); got[0].Cmp(curve.Gx) != 0 || got[1].Cmp(curve.Gy) != 0 {
+			t.Errorf("∞ + G: got: (%d, %d), want: (%d, %d)", got[0], got[1], curve.Gx, curve.Gy)
+		}
+		if got := curve.ToAffine(inf); got[0].Sign() != 0 || got[1].Sign() != 0 {
+			t.Errorf("∞: got: (%d, %d), want: (0, 0)", got[0], got[1])
+		}
+	})
+}
+
+func TestCombinedMultSub(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		_, qx, qy, _ := curve.GenerateKey(rand.Reader)
+		m, _ := rand.Int(rand.Reader, curve.N)
+		n, _ := rand.Int(rand.Reader, curve.N)
+		gx, gy := curve.Gx, curve.Gy
+		ngx, ngy := curve.Neg(gx, gy)
+
+		for _, c := range []struct {
+			qx, qy, m, n *big.Int
+		}{
+			{qx, qy, m, n},
+			{qx, qy, big.NewInt(0), n},
+			{qx, qy, m, big.NewInt(0)},
+			{qx, qy, new(big.Int).Neg(m), new(big.Int).Neg(n)},
+			{gx, gy, m, n},
+			{gx, gy, m, m},
+			{ngx, ngy, m, n},
+			{new(big.Int), new(big.Int), m, n},
+		} {
+			x1, y1 := curve.ScalarBaseMult(c.m)
+			x2, y2 := curve.ScalarMult(c.qx, c.qy, c.n)
+			if x2.Sign() != 0 || y2.Sign() != 0 {
+				x2, y2 = curve.Neg(x2, y2)
+			}
+			wx, wy := curve.Add(x1, y1, x2, y2)
+
+			x, y := curve.CombinedMultSub(c.qx, c.qy, c.m, c.n)
+			if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+				t.Errorf("Q = (%v, %v), m = %v, n = %v got: (%v, %v), want: (%v, %v)", c.qx, c.qy, c.m, c.n, x, y, wx, wy)
+			}
+		}
+	})
+}
+
+func TestIsInfinity(t *testing.T) {
+	for _, c := range []struct {
+		x, y int64
+		want bool
+	}{
+		{0, 0, true},
+		{0, 1, false},
+		{1, 0, false},
+		{-1, 0, false},
+		{5, 116, false},
+	} {
+		if got := IsInfinity(big.NewInt(c.x), big.NewInt(c.y)); got != c.want {
+			t.Errorf("(%d, %d) got: %v, want: %v", c.x, c.y, got, c.want)
+		}
+	}
+
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		if x, y := curve.ScalarBaseMult(curve.N); !IsInfinity(x, y) {
+			t.Errorf("N·G = (%d, %d) is not ∞", x, y)
+		}
+		if IsInfinity(curve.Gx, curve.Gy) {
+			t.Errorf("G is ∞")
+		}
+	})
+}
+
+func TestInSubgroup(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		x, y := curve.ScalarBaseMult(big.NewInt(12345))
+		if !curve.InSubgroup(x, y) {
+			t.Errorf("12345·G is not in the subgroup")
+		}
+		if curve.InSubgroup(x, new(big.Int).Add(y, big.NewInt(1))) {
+			t.Errorf("off-curve point is in the subgroup")
+		}
+		if curve.InSubgroup(new(big.Int), new(big.Int)) {
+			t.Errorf("∞ is in the subgroup")
+		}
+	})
+
+	// with H = 1, N is not used at all
+	curve := sampleCurves()["SMALL"]
+	curve.N = big.NewInt(7)
+	if !curve.InSubgroup(curve.Gx, curve.Gy) {
+		t.Errorf("H = 1: G is not in the subgroup")
+	}
+
+	// #E = 80, base point of order 5
+	curve = &Curve{
+		P:       big.NewInt(97),
+		A:       big.NewInt(46),
+		B:       big.NewInt(74),
+		Gx:      big.NewInt(49),
+		Gy:      big.NewInt(45),
+		N:       big.NewInt(5),
+		H:       big.NewInt(16),
+		BitSize: 7,
+	}
+	if !curve.InSubgroup(curve.Gx, curve.Gy) {
+		t.Errorf("H = 16: G is not in the subgroup")
+	}
+	// (57, 0) has order 2
+	if curve.InSubgroup(big.NewInt(57), big.NewInt(0)) {
+		t.Errorf("H = 16: (57, 0) is in the subgroup")
+	}
+}
+
+func TestLiftXEven(t *testing.T) {
+	curve := sampleCurves()["S256"]
+
+	// public keys of the BIP-340 test vectors, with the even y they lift to
+	for _, c := range []struct{ x, y string }{
+		{"f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+			"388f7b0f632de8140fe337e62a37f3566500a99934c2231b6cb9fd7584b8e672"},
+		{"dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659",
+			"2ce19b946c4ee58546f5251d441a065ea50735606985e5b228788bec4e582898"},
+		{"dd308afec5777e13121fa72b9cc1b7cc0139715309b086c960e18fd969774eb8",
+			"f594bb5f72b37faae396a4259ea64ed5e6fdeb2a51c6467582b275925fab1394"},
+		{"25d1dff95105f5253c4022f628a996ad3a0d95fbf21d468a1b33f8c160d8f517",
+			"f3014853bcbe349bbe38fda97573f042378c4f30a37e7e02501c51180b632786"},
+	} {
+		b, _ := hex.DecodeString(c.x)
+		x, y, err := curve.LiftXEven(b)
+		if err != nil {
+			t.Errorf("%s: got error: %v", c.x, err)
+			continue
+		}
+		if got := hex.EncodeToString(y.FillBytes(make([]byte, 32))); got != c.y || x.Cmp(new(big.Int).SetBytes(b)) != 0 {
+			t.Errorf("%s: got: y = %s, want: %s", c.x, got, c.y)
+		}
+	}
+
+	// the first key is that of the secret key 3
+	x, y := curve.ScalarBaseMult(big.NewInt(3))
+	if y.Bit(0) == 1 {
+		x, y = curve.Neg(x, y)
+	}
+	gx, gy, err := curve.LiftXEven(x.FillBytes(make([]byte, 32)))
+	if err != nil || gx.Cmp(x) != 0 || gy.Cmp(y) != 0 {
+		t.Errorf("3·G: got: (%x, %x), %v, want: (%x, %x)", gx, gy, err, x, y)
+	}
+
+	for _, s := range []string{
+		// not on the curve
+		"eefdea4cdb677750a420fee807eacf21eb9898ae79b9768766e4faa04a2d4a34",
+		// exceeds the field size
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc30",
+		// too short
+		"f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036",
+	} {
+		b, _ := hex.DecodeString(s)
+		if _, _, err := curve.LiftXEven(b); err != ErrInvalidPublicKey {
+			t.Errorf("%s: got: %v, want: %v", s, err, ErrInvalidPublicKey)
+		}
+	}
+}
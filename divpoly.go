@@ -1,6 +1,30 @@
 package ecc
 
-import "math/big"
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+)
+
+var (
+	// ErrDivPolyCache is returned when an imported division polynomial cache
+	// does not belong to the curve or is malformed.
+	ErrDivPolyCache = errors.New("ecc: invalid division polynomial cache")
+
+	// ErrTorsionBound is returned by TorsionPoints when ℓ is out of range.
+	ErrTorsionBound = errors.New("ecc: torsion order out of range")
+)
+
+// maxTorsionEll bounds ℓ in TorsionPoints: DivPoly(ℓ) has degree about ℓ²/2,
+// and finding its roots takes log P multiplications modulo it.
+const maxTorsionEll = 31
+
+// divPolyCache is the serialized form of a division polynomial cache.
+type divPolyCache struct {
+	P, A, B *big.Int
+	Polys   map[int64][]*big.Int
+}
 
 func (c *Curve) poly() Poly {
 	return NewPolyFromBigInt(c.B, c.A, new(big.Int), big.NewInt(1))
@@ -21,7 +45,14 @@ func (c *Curve) DivPoly(n int64) Poly {
 	}
 
 	q, f := c.P, c.poly()
-	a, b := int(c.A.Int64()), int(c.B.Int64())
+	a, b := c.A, c.B
+	mul := func(k int64, xs ...*big.Int) *big.Int {
+		r := big.NewInt(k)
+		for _, x := range xs {
+			r.Mul(r, x)
+		}
+		return r
+	}
 
 	switch n {
 	case 0:
@@ -29,43 +60,190 @@ func (c *Curve) DivPoly(n int64) Poly {
 	case 1:
 		return cache(c, n, NewPolyFromInt(1))
 	case 2:
-		return cache(c, n, f.Mul(NewPolyFromInt(4), q))
+		return cache(c, n, f.ScaleInt(big.NewInt(4), q))
 	case 3:
-		return cache(c, n, NewPolyFromInt(-a*a, 12*b, 6*a, 0, 3).sanitize(c.P))
+		return cache(c, n, NewPolyFromBigInt(
+			mul(-1, a, a), mul(12, b), mul(6, a), new(big.Int), big.NewInt(3),
+		).sanitize(q))
 	case 4:
-		return cache(c, n,
-			NewPolyFromInt(-64*b*b-8*a*a*a, -32*a*b, -40*a*a, 160*b, 40*a, 0, 8).
-				Mul(f, q))
+		c0 := mul(-64, b, b)
+		c0.Add(c0, mul(-8, a, a, a))
+		return cache(c, n, NewPolyFromBigInt(
+			c0, mul(-32, a, b), mul(-40, a, a), mul(160, b), mul(40, a),
+			new(big.Int), big.NewInt(8),
+		).sanitize(q).Mul(f, q))
 	}
 
+	dp, err := c.divPolyStep(n, c.DivPoly)
+	if err != nil {
+		// the recursion guarantees the divisions to be exact
+		panic("ecc: internal error: " + err.Error())
+	}
+	return cache(c, n, dp)
+}
+
+// divPolyStep returns DivPoly(n), n > 4, by the recurrence from the
+// polynomials get returns for n/2 - 2, ..., n/2 + 2, and 2. It fails if they
+// are not division polynomials and a division is not exact.
+func (c *Curve) divPolyStep(n int64, get func(int64) Poly) (Poly, error) {
+	q, f := c.P, c.poly()
 	m := n / 2
 
-	p2m := c.DivPoly(m - 2)
-	p1m := c.DivPoly(m - 1)
-	pm := c.DivPoly(m)
-	pm1 := c.DivPoly(m + 1)
-	pm2 := c.DivPoly(m + 2)
+	p2m := get(m - 2)
+	p1m := get(m - 1)
+	pm := get(m)
+	pm1 := get(m + 1)
+	pm2 := get(m + 2)
 
 	p1me2 := p1m.Exp(big.NewInt(2), q)
 	pme3 := pm.Exp(big.NewInt(3), q)
 	pm1e2 := pm1.Exp(big.NewInt(2), q)
 	pm1e3 := pm1.Exp(big.NewInt(3), q)
 
-	var dp Poly
+	var err error
 	if n&0x1 == 1 {
-		denominator := f.Mul(f, q).Mul(NewPolyFromInt(16), q)
+		denominator := f.Mul(f, q).ScaleInt(big.NewInt(16), q)
 		t1 := pm2.Mul(pme3, q)
 		t2 := p1m.Mul(pm1e3, q)
 		if m&0x1 == 0 {
-			t1, _ = t1.Div(denominator, q)
+			t1, err = t1.DivExact(denominator, q)
 		} else {
-			t2, _ = t2.Div(denominator, q)
+			t2, err = t2.DivExact(denominator, q)
+		}
+		if err != nil {
+			return nil, err
 		}
-		dp = t1.Sub(t2, q)
-	} else {
-		dp = pm.Mul(pm2.Mul(p1me2, q).Sub(p2m.Mul(pm1e2, q), q), q)
-		dp, _ = dp.Div(c.dpCache[2], q)
+		return t1.Sub(t2, q), nil
 	}
+	dp := pm.Mul(pm2.Mul(p1me2, q).Sub(p2m.Mul(pm1e2, q), q), q)
+	return dp.DivExact(get(2), q)
+}
 
-	return cache(c, n, dp)
+// divPolyShape reports whether dp, n > 4, has the degree and leading
+// coefficient of DivPoly(n) over F_p: (n²-1)/2 and n for odd n, which is ψ_n,
+// and (n²+2)/2 and 2n for even n, which is 2y·ψ_n. If p divides n, the
+// degree is lower and only the recurrence can tell.
+func divPolyShape(dp Poly, n int64, p *big.Int) bool {
+	deg, lead := (n*n-1)/2, big.NewInt(n)
+	if n&0x1 == 0 {
+		deg, lead = (n*n+2)/2, big.NewInt(2*n)
+	}
+	if lead.Mod(lead, p).Sign() == 0 {
+		return int64(dp.Deg()) < deg
+	}
+	return int64(dp.Deg()) == deg && dp[deg].Cmp(lead) == 0
+}
+
+// ExportDivPolyCache serializes the division polynomials computed so far,
+// together with P, A and B, so that ImportDivPolyCache can restore them in a
+// later run.
+func (c *Curve) ExportDivPolyCache() ([]byte, error) {
+	dc := divPolyCache{P: c.P, A: c.A, B: c.B, Polys: make(map[int64][]*big.Int)}
+	for n, dp := range c.dpCache {
+		dc.Polys[n] = dp
+	}
+	return json.Marshal(dc)
+}
+
+// ImportDivPolyCache adds the division polynomials serialized by
+// ExportDivPolyCache to the cache. It is an error, and nothing is imported,
+// if they were computed for a different curve, a coefficient is out of
+// range, one of the first few polynomials differs from its definition, or a
+// later one differs from what the recurrence gives from the ones before it.
+// A polynomial whose recurrence inputs are not all there can't be checked,
+// and is an error too.
+func (c *Curve) ImportDivPolyCache(data []byte) error {
+	var dc divPolyCache
+	if err := json.Unmarshal(data, &dc); err != nil {
+		return ErrDivPolyCache
+	}
+	P := c.P
+	congruent := func(x, y *big.Int) bool {
+		d := new(big.Int).Sub(x, y)
+		return d.Mod(d, P).Sign() == 0
+	}
+	if dc.P == nil || dc.A == nil || dc.B == nil || dc.P.Cmp(P) != 0 ||
+		!congruent(dc.A, c.A) || !congruent(dc.B, c.B) {
+		return ErrDivPolyCache
+	}
+
+	fresh := &Curve{P: c.P, A: c.A, B: c.B}
+	polys := make(map[int64]Poly, len(dc.Polys))
+	for n, coeffs := range dc.Polys {
+		if n < 0 || len(coeffs) == 0 {
+			return ErrDivPolyCache
+		}
+		for _, a := range coeffs {
+			if a == nil || a.Sign() < 0 || a.Cmp(P) >= 0 {
+				return ErrDivPolyCache
+			}
+		}
+		dp := Poly(coeffs)
+		if n <= 4 && !dp.Equal(fresh.DivPoly(n)) {
+			return ErrDivPolyCache
+		}
+		if n > 4 && !divPolyShape(dp, n, P) {
+			return ErrDivPolyCache
+		}
+		polys[n] = dp
+	}
+
+	// recompute the rest in order, so that each is checked against inputs
+	// which are either ψ_0..ψ_4 or already checked
+	get := func(k int64) Poly {
+		if k <= 4 {
+			return fresh.DivPoly(k)
+		}
+		return polys[k]
+	}
+	var rest []int64
+	for n := range polys {
+		if n > 4 {
+			rest = append(rest, n)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+	for _, n := range rest {
+		m := n / 2
+		for k := m - 2; k <= m+2; k++ {
+			if get(k) == nil {
+				return ErrDivPolyCache
+			}
+		}
+		dp, err := c.divPolyStep(n, get)
+		if err != nil || !dp.Equal(polys[n]) {
+			return ErrDivPolyCache
+		}
+	}
+
+	if c.dpCache == nil {
+		c.dpCache = make(map[int64]Poly)
+	}
+	for n, dp := range polys {
+		c.dpCache[n] = dp
+	}
+	return nil
+}
+
+// TorsionPoints returns the affine points of E(F_p) other than ∞ that are
+// killed by ℓ, in ascending order of x. Their x-coordinates are the roots of
+// DivPoly(ℓ) in F_p, and the points are those roots that lift to the curve.
+// It is an error if ℓ < 2 or ℓ > 31.
+func (c *Curve) TorsionPoints(ell int64) ([][2]*big.Int, error) {
+	if ell < 2 || ell > maxTorsionEll {
+		return nil, ErrTorsionBound
+	}
+
+	var points [][2]*big.Int
+	for _, x := range c.DivPoly(ell).Roots(c.P) {
+		y1, y2, ok := c.LiftX(x)
+		if !ok {
+			continue
+		}
+		points = append(points, [2]*big.Int{x, y1})
+		if y1.Cmp(y2) != 0 {
+			points = append(points, [2]*big.Int{new(big.Int).Set(x), y2})
+		}
+	}
+	return points, nil
 }
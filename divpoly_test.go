@@ -45,3 +45,196 @@ func TestDivisionPolynomial(t *testing.T) {
 		}
 	}
 }
+
+func TestDivPolyCache(t *testing.T) {
+	c := &Curve{P: big.NewInt(7919), A: big.NewInt(1001), B: big.NewInt(75)}
+	for n := int64(0); n <= 13; n++ {
+		c.DivPoly(n)
+	}
+	data, err := c.ExportDivPolyCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Curve{P: big.NewInt(7919), A: big.NewInt(1001 - 7919), B: big.NewInt(75)}
+	if err := r.ImportDivPolyCache(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.dpCache) != len(c.dpCache) {
+		t.Errorf("got: %d polynomials, want: %d", len(r.dpCache), len(c.dpCache))
+	}
+	fresh := &Curve{P: c.P, A: c.A, B: c.B}
+	for n := int64(0); n <= 15; n++ {
+		if got, want := r.DivPoly(n), fresh.DivPoly(n); !got.Equal(want) {
+			t.Errorf("DivPoly(%d): got: %v, want: %v", n, got, want)
+		}
+	}
+
+	other := &Curve{P: big.NewInt(7919), A: big.NewInt(1001), B: big.NewInt(76)}
+	if err := other.ImportDivPolyCache(data); err != ErrDivPolyCache {
+		t.Errorf("other curve: got: %v, want: %v", err, ErrDivPolyCache)
+	}
+	if other.dpCache != nil {
+		t.Errorf("other curve: imported %d polynomials", len(other.dpCache))
+	}
+
+	// ψ_3 = 3x⁴ + 6ax² + 12bx - a² with its x coefficient changed
+	bad := &Curve{P: c.P, A: c.A, B: c.B, dpCache: map[int64]Poly{3: c.DivPoly(3).Clone(0)}}
+	bad.dpCache[3][1].Add(bad.dpCache[3][1], big.NewInt(1))
+	data, err = bad.ExportDivPolyCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Curve{P: c.P, A: c.A, B: c.B}).ImportDivPolyCache(data); err != ErrDivPolyCache {
+		t.Errorf("bad ψ_3: got: %v, want: %v", err, ErrDivPolyCache)
+	}
+	if err := r.ImportDivPolyCache([]byte("{")); err != ErrDivPolyCache {
+		t.Errorf("malformed: got: %v, want: %v", err, ErrDivPolyCache)
+	}
+
+	// corrupted entries beyond ψ_4
+	corrupt := map[string]func(dp Poly) Poly{
+		"leading coefficient": func(dp Poly) Poly {
+			dp[len(dp)-1].Add(dp[len(dp)-1], big.NewInt(1))
+			return dp
+		},
+		"degree": func(dp Poly) Poly {
+			return append(dp, big.NewInt(1))
+		},
+		"constant term": func(dp Poly) Poly {
+			dp[0].Add(dp[0], big.NewInt(1)).Mod(dp[0], c.P)
+			return dp
+		},
+		"middle coefficient": func(dp Poly) Poly {
+			k := len(dp) / 2
+			dp[k].Add(dp[k], big.NewInt(1)).Mod(dp[k], c.P)
+			return dp
+		},
+	}
+	for k := int64(14); k <= 24; k++ {
+		c.DivPoly(k)
+	}
+	for name, f := range corrupt {
+		for _, n := range []int64{5, 8, 9, 17, 24} {
+			bad := &Curve{P: c.P, A: c.A, B: c.B, dpCache: make(map[int64]Poly)}
+			for k := int64(0); k <= 24; k++ {
+				bad.dpCache[k] = c.DivPoly(k).Clone(0)
+			}
+			bad.dpCache[n] = f(bad.dpCache[n])
+			data, err := bad.ExportDivPolyCache()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := (&Curve{P: c.P, A: c.A, B: c.B}).ImportDivPolyCache(data); err != ErrDivPolyCache {
+				t.Errorf("ψ_%d with bad %s: got: %v, want: %v", n, name, err, ErrDivPolyCache)
+			}
+		}
+	}
+
+	// ψ_13 needs ψ_4..ψ_8, so without ψ_7 it can't be checked
+	gap := &Curve{P: c.P, A: c.A, B: c.B, dpCache: make(map[int64]Poly)}
+	for _, k := range []int64{0, 1, 2, 3, 4, 5, 6, 8, 13} {
+		gap.dpCache[k] = c.DivPoly(k).Clone(0)
+	}
+	data, err = gap.ExportDivPolyCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Curve{P: c.P, A: c.A, B: c.B}).ImportDivPolyCache(data); err != ErrDivPolyCache {
+		t.Errorf("ψ_13 without ψ_7: got: %v, want: %v", err, ErrDivPolyCache)
+	}
+}
+
+func TestDivPolyLargeCoefficients(t *testing.T) {
+	// A and B beyond int64 must give the same polynomials as their residues
+	big19 := new(big.Int).Lsh(big.NewInt(19), 70)
+	small := &Curve{P: big.NewInt(19), A: big.NewInt(2), B: big.NewInt(1)}
+	large := &Curve{
+		P: big.NewInt(19),
+		A: new(big.Int).Add(big19, big.NewInt(2)),
+		B: new(big.Int).Sub(big.NewInt(1), big19),
+	}
+	for n := int64(0); n <= 9; n++ {
+		if got, want := large.DivPoly(n), small.DivPoly(n); !got.Equal(want) {
+			t.Errorf("DivPoly(%d): got: %v, want: %v", n, got, want)
+		}
+	}
+
+	// a curve over the 64-bit prime 2^64 - 59
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(59))
+	c := &Curve{
+		P: p,
+		A: BigFromDecimal("12345678901234567890"),
+		B: BigFromDecimal("9876543210987654321"),
+	}
+	a, b := c.A, c.B
+	for _, x := range []*big.Int{big.NewInt(0), big.NewInt(3), BigFromDecimal("18000000000000000000")} {
+		// ψ_3 = 3x⁴ + 6ax² + 12bx - a²
+		want := new(big.Int).Exp(x, big.NewInt(4), nil)
+		want.Mul(want, big.NewInt(3))
+		want.Add(want, new(big.Int).Mul(big.NewInt(6), new(big.Int).Mul(a, new(big.Int).Mul(x, x))))
+		want.Add(want, new(big.Int).Mul(big.NewInt(12), new(big.Int).Mul(b, x)))
+		want.Sub(want, new(big.Int).Mul(a, a))
+		want.Mod(want, p)
+		if got := c.DivPoly(3).Eval(x, p); got.Cmp(want) != 0 {
+			t.Errorf("ψ_3(%d): got: %d, want: %d", x, got, want)
+		}
+	}
+	if psi := c.DivPoly(7); psi.Deg() != 24 || psi[24].Int64() != 7 {
+		t.Errorf("ψ_7: got degree %d, leading coefficient %d, want: 24, 7", psi.Deg(), psi[psi.Deg()])
+	}
+}
+
+func TestTorsionPoints(t *testing.T) {
+	curves := map[string]*Curve{
+		"Z/12xZ/2": {P: big.NewInt(29), A: big.NewInt(1), B: big.NewInt(2)},
+		"Z/24xZ/4": {P: big.NewInt(97), A: big.NewInt(2), B: big.NewInt(10)},
+	}
+	for name, c := range sampleCurves() {
+		if c.P.BitLen() <= 16 {
+			curves[name] = c
+		}
+	}
+
+	for name, c := range curves {
+		// every affine point, by enumeration
+		var all [][2]*big.Int
+		for x := int64(0); x < c.P.Int64(); x++ {
+			if y1, y2, ok := c.LiftX(big.NewInt(x)); ok {
+				all = append(all, [2]*big.Int{big.NewInt(x), y1})
+				if y1.Cmp(y2) != 0 {
+					all = append(all, [2]*big.Int{big.NewInt(x), y2})
+				}
+			}
+		}
+
+		for ell := int64(2); ell <= 12; ell++ {
+			points, err := c.TorsionPoints(ell)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			for _, pt := range points {
+				if x, y := c.ScalarMult(pt[0], pt[1], big.NewInt(ell)); x.Sign() != 0 || y.Sign() != 0 {
+					t.Errorf("%s: %d·(%v, %v) got: (%v, %v), want: ∞", name, ell, pt[0], pt[1], x, y)
+				}
+			}
+
+			want := 0
+			for _, pt := range all {
+				if x, y := c.ScalarMult(pt[0], pt[1], big.NewInt(ell)); x.Sign() == 0 && y.Sign() == 0 {
+					want++
+				}
+			}
+			if len(points) != want {
+				t.Errorf("%s: %d-torsion got: %d points, want: %d", name, ell, len(points), want)
+			}
+		}
+	}
+
+	c := sampleCurves()["TOY"]
+	for _, ell := range []int64{-1, 0, 1, 32} {
+		if _, err := c.TorsionPoints(ell); err != ErrTorsionBound {
+			t.Errorf("ℓ = %d got: %v, want: %v", ell, err, ErrTorsionBound)
+		}
+	}
+}
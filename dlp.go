@@ -1,29 +1,52 @@
 package ecc
 
 import (
+	"encoding/binary"
+	"errors"
+	"io"
 	"math/big"
 	"math/rand"
 	"sort"
-	"time"
+	"sync"
+	"sync/atomic"
 )
 
+// maxShankSteps bounds the baby steps, and so the table, of ShankWithN.
+const maxShankSteps = 1 << 22
+
 // Shank algorithm for the ECDLP
 func (c *Curve) Shank(px, py, hx, hy *big.Int) *big.Int {
+	return c.ShankWithN(px, py, hx, hy, c.N)
+}
+
+// ShankWithN is Shank for a P of the given order instead of N, such as the
+// subgroups PohligHellman works in. It takes ⌈√order⌉ baby and giant steps
+// and returns nil if the logarithm is not found by then, which happens when
+// order is too small for P. It also returns nil, without searching, if
+// √order exceeds maxShankSteps, as the table of baby steps would not fit in
+// memory.
+func (c *Curve) ShankWithN(px, py, hx, hy, order *big.Int) *big.Int {
 	if !c.IsOnCurve(px, py) {
 		return nil
 	}
+	if IsInfinity(hx, hy) {
+		return new(big.Int)
+	}
 
-	sqrtN := new(big.Int).Sqrt(c.N)
+	sqrtN := new(big.Int).Sqrt(order)
 	sqrtN.Add(sqrtN, big.NewInt(1))
-	rx, ry := new(big.Int), new(big.Int)
+	if !sqrtN.IsInt64() || sqrtN.Int64() > maxShankSteps {
+		return nil
+	}
 	precomputed := make(map[string]*big.Int)
 
-	for a := big.NewInt(1); a.Cmp(sqrtN) <= 0; a.Add(a, big.NewInt(1)) {
-		rx, ry = c.Add(rx, ry, px, py)
-		precomputed[string(c.Marshal(rx, ry))] = new(big.Int).Set(a)
+	for a, r := range c.Multiples(px, py, int(sqrtN.Int64())) {
+		if a > 0 {
+			precomputed[string(c.Marshal(r[0], r[1]))] = big.NewInt(int64(a))
+		}
 	}
 
-	rx, ry = hx, hy
+	rx, ry := hx, hy
 	npx, npy := c.Neg(px, py)
 	sx, sy := c.ScalarMult(npx, npy, sqrtN)
 
@@ -38,72 +61,445 @@ func (c *Curve) Shank(px, py, hx, hy *big.Int) *big.Int {
 	return nil
 }
 
+// rhoStep moves the Point R = aP + bQ of a Pollard rho walk for Q = kP one
+// step, updating a and b to match. The step depends only on R, so walks that
+// meet stay together.
+func (c *Curve) rhoStep(px, py, hx, hy, x, y, a, b *big.Int) (*big.Int, *big.Int, *big.Int, *big.Int) {
+	N := c.N
+	switch new(big.Int).Mod(x, big.NewInt(3)).Int64() {
+	case 0: // S1: P+R, a+1, b
+		x, y = c.Add(px, py, x, y)
+		a.Add(a, big.NewInt(1))
+		return x, y, a.Mod(a, N), b
+	case 1: // S2: 2R, 2a, 2b
+		x, y = c.ScalarMult(x, y, big.NewInt(2))
+		a.Add(a, a)
+		b.Add(b, b)
+		return x, y, a.Mod(a, N), b.Mod(b, N)
+	default: // S3: Q+R, a, b+1
+		x, y = c.Add(hx, hy, x, y)
+		b.Add(b, big.NewInt(1))
+		return x, y, a, b.Mod(b, N)
+	}
+}
+
 // PollardRho algorithm for the ECDLP
+// The walks are seeded from Rand, so a fixed Rand makes the result and the
+// work done reproducible.
 func (c *Curve) PollardRho(px, py, hx, hy *big.Int) *big.Int {
-	if !c.IsOnCurve(px, py) {
-		return nil
+	k, _ := c.pollardRho(px, py, hx, hy)
+	return k
+}
+
+// rhoAttempts bounds the number of random walks the Pollard rho variants
+// start, and rhoWalk the number of steps taken in each.
+const (
+	rhoAttempts = 100000
+	rhoWalk     = 3000
+)
+
+// rhoMaxSteps caps the steps PollardRhoParallel takes in total, for
+// group orders whose √N-based budget would not fit or never end.
+const rhoMaxSteps = rhoAttempts * rhoWalk
+
+// rhoRand returns a generator for the random choices of a Pollard rho walk,
+// seeded from the curve's Rand.
+func (c *Curve) rhoRand() (*rand.Rand, error) {
+	var seed [8]byte
+	if _, err := io.ReadFull(c.random(), seed[:]); err != nil {
+		return nil, err
 	}
+	return rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(seed[:])))), nil
+}
 
+// rhoSetup returns a random starting Point R = aP + bQ for a Pollard rho walk.
+func (c *Curve) rhoSetup(rnd *rand.Rand, px, py, hx, hy *big.Int) (*big.Int, *big.Int, *big.Int, *big.Int) {
 	N := c.N
+	a, b := new(big.Int).Rand(rnd, N), new(big.Int).Rand(rnd, N)
+	vx, vy := c.ScalarMult(px, py, a)
+	ux, uy := c.ScalarMult(hx, hy, b)
+	x, y := c.Add(vx, vy, ux, uy)
+	return x, y, a, b
+}
 
+// rhoSolve returns k with Q = kP from a collision a1P + b1Q = a2P + b2Q, or
+// nil if the collision doesn't determine it.
+func (c *Curve) rhoSolve(px, py, hx, hy, a1, b1, a2, b2 *big.Int) *big.Int {
+	N := c.N
+	if b1.Cmp(b2) == 0 {
+		return nil
+	}
+	k := new(big.Int).Sub(a1, a2)
+	k.Mod(k, N)
+	inv := new(big.Int).Sub(b2, b1)
+	if inv.ModInverse(inv.Mod(inv, N), N) == nil {
+		return nil
+	}
+	k.Mul(k, inv)
+	k.Mod(k, N)
+	if tx, ty := c.ScalarMult(px, py, k); tx.Cmp(hx) == 0 && ty.Cmp(hy) == 0 {
+		return k
+	}
+	return nil
+}
+
+// pollardRho is PollardRho with Floyd's cycle detection. It also returns the
+// number of steps of the walk taken.
+func (c *Curve) pollardRho(px, py, hx, hy *big.Int) (*big.Int, int) {
+	if !c.IsOnCurve(px, py) {
+		return nil, 0
+	}
+	if IsInfinity(hx, hy) {
+		return new(big.Int), 0
+	}
+
+	evals := 0
 	f := func(x, y, a, b *big.Int) (*big.Int, *big.Int, *big.Int, *big.Int) {
-		switch new(big.Int).Mod(x, big.NewInt(3)).Int64() {
-		case 0: // S1: P+R, a+1, b
-			x, y = c.Add(px, py, x, y)
-			a.Add(a, big.NewInt(1))
-			return x, y, a.Mod(a, N), b
-		case 1: // S2: 2R, 2a, 2b
-			x, y = c.ScalarMult(x, y, big.NewInt(2))
-			a.Add(a, a)
-			b.Add(b, b)
-			return x, y, a.Mod(a, N), b.Mod(b, N)
-		default: // S3: Q+R, a, b+1
-			x, y = c.Add(hx, hy, x, y)
-			b.Add(b, big.NewInt(1))
-			return x, y, a, b.Mod(b, N)
-		}
-	}
-
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-	setup := func() (*big.Int, *big.Int, *big.Int, *big.Int) {
-		a, b := new(big.Int).Rand(rnd, N), new(big.Int).Rand(rnd, N)
-		vx, vy := c.ScalarMult(px, py, a)
-		ux, uy := c.ScalarMult(hx, hy, b)
-		x, y := c.Add(vx, vy, ux, uy)
-		return x, y, a, b
-	}
-
-	for i := 0; i < 100000; i++ {
-		x1, y1, a1, b1 := setup()
-		x2, y2, a2, b2 := setup()
-		for j := 0; j < 1000; j++ {
+		evals++
+		return c.rhoStep(px, py, hx, hy, x, y, a, b)
+	}
+
+	rnd, err := c.rhoRand()
+	if err != nil {
+		return nil, 0
+	}
+	for i := 0; i < rhoAttempts; i++ {
+		x1, y1, a1, b1 := c.rhoSetup(rnd, px, py, hx, hy)
+		x2, y2, a2, b2 := c.rhoSetup(rnd, px, py, hx, hy)
+		for j := 0; j < rhoWalk/3; j++ {
 			x1, y1, a1, b1 = f(x1, y1, a1, b1)
 			x2, y2, a2, b2 = f(f(x2, y2, a2, b2))
 			if x1.Cmp(x2) == 0 && y1.Cmp(y2) == 0 {
-				if b1.Cmp(b2) == 0 {
-					break
+				if k := c.rhoSolve(px, py, hx, hy, a1, b1, a2, b2); k != nil {
+					return k, evals
 				}
-				a1.Sub(a1, a2)
-				a1.Mod(a1, N)
-				b2.Sub(b2, b1)
-				b2.Mod(b2, N)
-				b2.ModInverse(b2, N)
-				a1.Mul(a1, b2)
-				a1.Mod(a1, N)
-				tx, ty := c.ScalarMult(px, py, a1)
-				if tx.Cmp(hx) == 0 && ty.Cmp(hy) == 0 {
-					return a1
+				break
+			}
+		}
+	}
+
+	return nil, evals
+}
+
+// PollardRhoBrent is PollardRho with Brent's cycle detection, which takes
+// about a quarter fewer steps of the walk than Floyd's: a single walk runs
+// ahead, and the Point it is compared with jumps to it each time the distance
+// between them reaches a power of two.
+func (c *Curve) PollardRhoBrent(px, py, hx, hy *big.Int) *big.Int {
+	k, _ := c.pollardRhoBrent(px, py, hx, hy)
+	return k
+}
+
+// pollardRhoBrent is PollardRhoBrent, also returning the number of steps of
+// the walk taken.
+func (c *Curve) pollardRhoBrent(px, py, hx, hy *big.Int) (*big.Int, int) {
+	if !c.IsOnCurve(px, py) {
+		return nil, 0
+	}
+	if IsInfinity(hx, hy) {
+		return new(big.Int), 0
+	}
+
+	evals := 0
+	rnd, err := c.rhoRand()
+	if err != nil {
+		return nil, 0
+	}
+	for i := 0; i < rhoAttempts; i++ {
+		x1, y1, a1, b1 := c.rhoSetup(rnd, px, py, hx, hy)
+		x2, y2 := x1, y1
+		a2, b2 := new(big.Int).Set(a1), new(big.Int).Set(b1)
+		power, lam := 1, 0
+		for j := 0; j < rhoWalk; j++ {
+			x2, y2, a2, b2 = c.rhoStep(px, py, hx, hy, x2, y2, a2, b2)
+			evals++
+			lam++
+			if x1.Cmp(x2) == 0 && y1.Cmp(y2) == 0 {
+				if k := c.rhoSolve(px, py, hx, hy, a1, b1, a2, b2); k != nil {
+					return k, evals
 				}
 				break
 			}
+			if lam == power {
+				x1, y1 = x2, y2
+				a1.Set(a2)
+				b1.Set(b2)
+				power *= 2
+				lam = 0
+			}
+		}
+	}
+
+	return nil, evals
+}
+
+// DefaultDistinguishedPoint returns the distinguished-point rule
+// PollardRhoParallel uses when none is given: the low N.BitLen()/4 bits of x
+// are zero, so that walks report about once every N^(1/4) steps.
+func (c *Curve) DefaultDistinguishedPoint() func(x, y *big.Int) bool {
+	bits := c.N.BitLen() / 4
+	return func(x, y *big.Int) bool {
+		for i := 0; i < bits; i++ {
+			if x.Bit(i) != 0 {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// rhoPoint is a distinguished Point R = aP + bQ reached by a walk.
+type rhoPoint struct {
+	x, y, a, b *big.Int
+}
+
+// PollardRhoParallel is the parallel Pollard rho of van Oorschot and Wiener.
+// workers walks run at once and report only the distinguished points they
+// reach, as decided by dp, or DefaultDistinguishedPoint if dp is nil; two
+// walks that meet are found at the next distinguished point. A rarer property
+// means less memory and communication but longer walks. Walks that don't
+// reach a distinguished point within 20·2^(N.BitLen()/4) steps are restarted,
+// and nil is returned if no logarithm is found within 1000·√N steps in total,
+// or 3·10⁸ steps, whichever is fewer.
+// Each worker is seeded from Rand, but the order in which they report varies
+// with scheduling.
+func (c *Curve) PollardRhoParallel(px, py, hx, hy *big.Int, workers int, dp func(x, y *big.Int) bool) *big.Int {
+	return c.pollardRhoParallel(px, py, hx, hy, workers, dp, rhoMaxSteps)
+}
+
+// pollardRhoParallel is PollardRhoParallel with at most maxSteps steps in
+// total, in place of rhoMaxSteps.
+func (c *Curve) pollardRhoParallel(px, py, hx, hy *big.Int, workers int, dp func(x, y *big.Int) bool, maxSteps int64) *big.Int {
+	if !c.IsOnCurve(px, py) {
+		return nil
+	}
+	if IsInfinity(hx, hy) {
+		return new(big.Int)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if dp == nil {
+		dp = c.DefaultDistinguishedPoint()
+	}
+
+	N := c.N
+	budget := maxSteps
+	if b := new(big.Int).Sqrt(N); b.BitLen() < 53 {
+		budget = min(budget, 1000*(b.Int64()+1))
+	}
+	maxWalk := budget
+	if shift := N.BitLen() / 4; shift < 58 {
+		maxWalk = min(maxWalk, 20*(int64(1)<<shift))
+	}
+
+	rnds := make([]*rand.Rand, workers)
+	for w := range rnds {
+		var err error
+		if rnds[w], err = c.rhoRand(); err != nil {
+			return nil
+		}
+	}
+
+	done := make(chan struct{})
+	points := make(chan rhoPoint)
+	var steps int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(rnd *rand.Rand) {
+			defer wg.Done()
+			for atomic.LoadInt64(&steps) < budget {
+				a, b := new(big.Int).Rand(rnd, N), new(big.Int).Rand(rnd, N)
+				vx, vy := c.ScalarMult(px, py, a)
+				ux, uy := c.ScalarMult(hx, hy, b)
+				x, y := c.Add(vx, vy, ux, uy)
+				for i := int64(0); i < maxWalk; i++ {
+					if atomic.AddInt64(&steps, 1) > budget {
+						return
+					}
+					x, y, a, b = c.rhoStep(px, py, hx, hy, x, y, a, b)
+					if dp(x, y) {
+						select {
+						case points <- rhoPoint{x, y, a, b}:
+						case <-done:
+							return
+						}
+						break
+					}
+				}
+			}
+		}(rnds[w])
+	}
+	go func() {
+		wg.Wait()
+		close(points)
+	}()
+	defer close(done)
+
+	seen := make(map[string]rhoPoint)
+	for r := range points {
+		key := string(c.Marshal(r.x, r.y))
+		o, ok := seen[key]
+		if !ok {
+			seen[key] = r
+			continue
+		}
+		if o.b.Cmp(r.b) == 0 {
+			continue
+		}
+		// aP + bQ = a'P + b'Q, so k = (a - a') / (b' - b)
+		k := new(big.Int).Sub(r.a, o.a)
+		inv := new(big.Int).Sub(o.b, r.b)
+		if inv.ModInverse(inv.Mod(inv, N), N) == nil {
+			continue
+		}
+		k.Mul(k, inv)
+		k.Mod(k, N)
+		if tx, ty := c.ScalarMult(px, py, k); tx.Cmp(hx) == 0 && ty.Cmp(hy) == 0 {
+			return k
 		}
 	}
 
 	return nil
 }
 
-func factorize(n *big.Int) []*big.Int {
-	pollardRho := func(n *big.Int) *big.Int {
+// DefaultTrialBound is the trial-division bound of Factorize.
+const DefaultTrialBound = 10000
+
+// Factorize returns the prime factors of n, with multiplicity, in ascending
+// order. It is FactorizeWithBound with DefaultTrialBound.
+func Factorize(n *big.Int) ([]*big.Int, error) {
+	return FactorizeWithBound(n, DefaultTrialBound)
+}
+
+// FactorizeWithBound returns the prime factors of n, with multiplicity, in
+// ascending order. Factors up to trialBound are found by trial division, and
+// the rest by Pollard's rho. The factors always multiply to n: if rho fails
+// to split a composite factor, that composite is among them and
+// ErrFactorization is returned too. It returns nil if n < 2.
+func FactorizeWithBound(n *big.Int, trialBound int64) ([]*big.Int, error) {
+	if n.Cmp(big.NewInt(2)) < 0 {
+		return nil, nil
+	}
+
+	var factors []*big.Int
+	nn := new(big.Int).Set(n)
+	for nn.Bit(0) == 0 {
+		nn.Rsh(nn, 1)
+		factors = append(factors, big.NewInt(2))
+	}
+
+	// trial division stops at √n, or as soon as what is left is prime
+	if !nn.ProbablyPrime(20) {
+		if root := new(big.Int).Sqrt(nn); root.IsInt64() && root.Int64() < trialBound {
+			trialBound = root.Int64()
+		}
+		q, r, bd := new(big.Int), new(big.Int), new(big.Int)
+		forEachPrime(trialBound, func(d int64) bool {
+			if d == 2 {
+				return true
+			}
+			bd.SetInt64(d)
+			if new(big.Int).Mul(bd, bd).Cmp(nn) > 0 {
+				return false
+			}
+			found := false
+			for {
+				q.QuoRem(nn, bd, r)
+				if r.Sign() != 0 {
+					break
+				}
+				nn.Set(q)
+				factors = append(factors, big.NewInt(d))
+				found = true
+			}
+			// a prime cofactor is left to factorizeRho, which keeps it whole
+			return !found || !nn.ProbablyPrime(20)
+		})
+	}
+
+	rest, ok := factorizeRho(nn)
+	factors = append(factors, rest...)
+	sort.Slice(factors, func(i, j int) bool {
+		return factors[i].Cmp(factors[j]) < 0
+	})
+	if !ok {
+		return factors, ErrFactorization
+	}
+	return factors, nil
+}
+
+// primeSegment is the length of the blocks forEachPrime sieves at a time.
+const primeSegment = 1 << 16
+
+// forEachPrime calls f on the primes up to bound in ascending order, until f
+// returns false. It sieves in blocks of primeSegment numbers, with the primes
+// it has found so far, so its memory grows with how far f lets it run and not
+// with bound.
+func forEachPrime(bound int64, f func(p int64) bool) {
+	if bound < 2 {
+		return
+	}
+
+	// base holds the primes found up to √hi; past the first block they all
+	// lie below lo, and within it each prime sieves as it is found
+	var base []int64
+	seg := make([]bool, primeSegment)
+	for lo := int64(2); ; {
+		hi := bound
+		if bound-lo >= primeSegment {
+			hi = lo + primeSegment - 1
+		}
+		clear(seg)
+		for _, p := range base {
+			if p > hi/p {
+				break
+			}
+			for j := max(p*p, lo+(p-lo%p)%p); j <= hi; j += p {
+				seg[j-lo] = true
+				if j > hi-p {
+					break
+				}
+			}
+		}
+		for i := lo; ; i++ {
+			if !seg[i-lo] {
+				if i <= hi/i {
+					for j := i * i; j <= hi; j += i {
+						seg[j-lo] = true
+					}
+				}
+				if i <= bound/i {
+					base = append(base, i)
+				}
+				if !f(i) {
+					return
+				}
+			}
+			if i == hi {
+				break
+			}
+		}
+		if hi == bound {
+			return
+		}
+		lo = hi + 1
+	}
+}
+
+// factorizeRho returns the prime factors of n by Pollard's rho, with
+// x -> x² + c for a few c. If it can't split a composite, that composite is
+// among the factors and it returns false.
+func factorizeRho(n *big.Int) ([]*big.Int, bool) {
+	if n.Cmp(big.NewInt(1)) == 0 {
+		return nil, true
+	}
+	if n.ProbablyPrime(20) {
+		return []*big.Int{new(big.Int).Set(n)}, true
+	}
+
+	pollardRho := func(n *big.Int, c int64) *big.Int {
 		xStatic := big.NewInt(2)
 		cycleSize := uint64(2)
 		x := big.NewInt(2)
@@ -112,9 +508,9 @@ func factorize(n *big.Int) []*big.Int {
 			if i == 20 {
 				return nil
 			}
-			for c := uint64(1); c <= cycleSize && factor.Cmp(big.NewInt(1)) <= 0; c++ {
+			for k := uint64(1); k <= cycleSize && factor.Cmp(big.NewInt(1)) <= 0; k++ {
 				x.Mul(x, x)
-				x.Add(x, big.NewInt(1))
+				x.Add(x, big.NewInt(c))
 				x.Mod(x, n)
 				factor.GCD(nil, nil, new(big.Int).Sub(x, xStatic), n)
 			}
@@ -124,41 +520,83 @@ func factorize(n *big.Int) []*big.Int {
 		return factor
 	}
 
-	var factors []*big.Int
-	nn := new(big.Int).Set(n)
-	for nn.Bit(0) == 0 {
-		nn.Rsh(nn, 1)
-		factors = append(factors, big.NewInt(2))
+	for c := int64(1); c <= 5; c++ {
+		if f := pollardRho(n, c); f != nil && f.Cmp(n) != 0 {
+			a, okA := factorizeRho(f)
+			b, okB := factorizeRho(new(big.Int).Div(n, f))
+			return append(a, b...), okA && okB
+		}
 	}
-	if nn.Cmp(big.NewInt(1)) == 0 {
-		return factors
+	return []*big.Int{new(big.Int).Set(n)}, false
+}
+
+// PohligHellman algorithm for the ECDLP. If N can't be fully factored, the
+// unsplit composites are skipped and k is found modulo the rest of N only.
+func (c *Curve) PohligHellman(px, py, hx, hy *big.Int) *big.Int {
+	if !c.IsOnCurve(px, py) {
+		return nil
 	}
-	if nn.ProbablyPrime(5) {
-		return append(factors, nn)
+	if IsInfinity(hx, hy) {
+		return new(big.Int)
 	}
-
-	for f := pollardRho(nn); f != nil && f.Cmp(nn) != 0; f = pollardRho(nn) {
-		factors = append(factors, f)
-		nn.Div(nn, f)
-		if nn.ProbablyPrime(5) {
-			return append(factors, nn)
+	factors, err := Factorize(c.N)
+	if err != nil {
+		primes := factors[:0]
+		for _, f := range factors {
+			if f.ProbablyPrime(20) {
+				primes = append(primes, f)
+			}
 		}
+		factors = primes
 	}
-	return factors
+	return c.pohligHellman(px, py, hx, hy, c.N, factors)
 }
 
-// PohligHellman algorithm for the ECDLP
-func (c *Curve) PohligHellman(px, py, hx, hy *big.Int) *big.Int {
+// ErrNoDiscreteLog is returned by DiscreteLog when H is not a multiple of P.
+var ErrNoDiscreteLog = errors.New("ecc: no discrete logarithm found")
+
+// DiscreteLog returns k in [0, groupOrder) with k·P = H, found by
+// Pohlig-Hellman in a group of the given order instead of N, such as a
+// subgroup or the whole group of a curve with a cofactor. groupOrder must be
+// a multiple of the order of P, else ErrInvalidOrder is returned, and must be
+// fully factored by Factorize, else ErrFactorization is returned. Unlike
+// PohligHellman, the answer is checked, and ErrNoDiscreteLog is returned if H
+// is not in the subgroup generated by P.
+func (c *Curve) DiscreteLog(px, py, hx, hy, groupOrder *big.Int) (*big.Int, error) {
 	if !c.IsOnCurve(px, py) {
-		return nil
+		return nil, ErrNotOnCurve
+	}
+	if !IsInfinity(hx, hy) && !c.IsOnCurve(hx, hy) {
+		return nil, ErrNotOnCurve
+	}
+	if groupOrder.Sign() <= 0 {
+		return nil, ErrInvalidOrder
+	}
+	if x, y := c.ScalarMult(px, py, groupOrder); !IsInfinity(x, y) {
+		return nil, ErrInvalidOrder
+	}
+	if IsInfinity(hx, hy) {
+		return new(big.Int), nil
 	}
 
-	N := new(big.Int).Set(c.N)
-	factors := factorize(N)
-	sort.SliceStable(factors, func(i, j int) bool {
-		return factors[i].Cmp(factors[j]) < 0
-	})
+	factors, err := Factorize(groupOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	k := c.pohligHellman(px, py, hx, hy, groupOrder, factors)
+	if k == nil {
+		return nil, ErrNoDiscreteLog
+	}
+	if x, y := c.ScalarMult(px, py, k); x.Cmp(hx) != 0 || y.Cmp(hy) != 0 {
+		return nil, ErrNoDiscreteLog
+	}
+	return k, nil
+}
 
+// pohligHellman solves k·P = H modulo each prime power in factors, the
+// sorted prime factorization of N, and combines the results by CRT.
+func (c *Curve) pohligHellman(px, py, hx, hy, N *big.Int, factors []*big.Int) *big.Int {
 	var res []*big.Int
 	for i, j := 0, 0; i < len(factors); i = j {
 		fi := factors[i]
@@ -169,24 +607,33 @@ func (c *Curve) PohligHellman(px, py, hx, hy *big.Int) *big.Int {
 		res = append(res, k)
 	}
 
-	dlp := c.Shank
-	if c.BitSize > 100 {
-		dlp = c.PollardRho
-	}
-
 	var dLogs []*big.Int
 	for _, factor := range res {
-		c.N.Set(factor)
 		t := new(big.Int).Div(N, factor)
 		x, y := c.ScalarMult(px, py, t)
 		qx, qy := c.ScalarMult(hx, hy, t)
-		k := dlp(x, y, qx, qy)
+		var k *big.Int
+		switch {
+		case IsInfinity(x, y):
+			// the order of P is coprime to factor
+			if !IsInfinity(qx, qy) {
+				return nil
+			}
+			k = new(big.Int)
+		case c.BitSize > 100:
+			// PollardRho works modulo N, so give it a copy of the curve
+			// with the order of the subgroup
+			sub := *c
+			sub.N = factor
+			k = sub.PollardRho(x, y, qx, qy)
+		default:
+			k = c.ShankWithN(x, y, qx, qy, factor)
+		}
 		if k == nil {
 			return nil
 		}
 		dLogs = append(dLogs, k)
 	}
-	c.N.Set(N)
 
 	return CRT(dLogs, res)
 }
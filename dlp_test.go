@@ -1,8 +1,13 @@
 package ecc
 
 import (
+	"fmt"
+	"math"
 	"math/big"
+	"math/rand"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestECDLP(t *testing.T) {
@@ -85,4 +90,443 @@ func TestECDLP(t *testing.T) {
 			t.Errorf("[PohligHellman-2] (%d,%d) want: %d, got: %d", hx, hy, want, k)
 		}
 	})
+
+	t.Run("PohligHellman-cofactor", func(t *testing.T) {
+		t.Parallel()
+		// the whole group of COFACTOR, generated by (2, 557)
+		curve := sampleCurves()["COFACTOR"]
+		curve.N = new(big.Int).Mul(curve.N, curve.H)
+		px, py := big.NewInt(2), big.NewInt(557)
+
+		for _, want := range []*big.Int{big.NewInt(1), big.NewInt(2521), big.NewInt(5043), big.NewInt(10083)} {
+			hx, hy := curve.ScalarMult(px, py, want)
+			k := curve.PohligHellman(px, py, hx, hy)
+			if k == nil || k.Cmp(want) != 0 {
+				t.Errorf("[PohligHellman-cofactor] (%d,%d) want: %d, got: %d", hx, hy, want, k)
+			}
+		}
+	})
+}
+
+func TestECDLPInfinity(t *testing.T) {
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		Gx:      big.NewInt(4023),
+		Gy:      big.NewInt(6036),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	px, py := curve.Gx, curve.Gy
+	hx, hy := curve.ScalarBaseMult(curve.N)
+
+	for name, dlp := range map[string]func(px, py, hx, hy *big.Int) *big.Int{
+		"Shank":           curve.Shank,
+		"PollardRho":      curve.PollardRho,
+		"PollardRhoBrent": curve.PollardRhoBrent,
+		"PohligHellman":   curve.PohligHellman,
+		"PollardRhoParallel": func(px, py, hx, hy *big.Int) *big.Int {
+			return curve.PollardRhoParallel(px, py, hx, hy, 2, nil)
+		},
+	} {
+		if k := dlp(px, py, hx, hy); k == nil || k.Sign() != 0 {
+			t.Errorf("[%s] want: 0, got: %d", name, k)
+		}
+	}
+}
+
+func TestShankWithN(t *testing.T) {
+	// N = 7889 = 7³·23
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		Gx:      big.NewInt(4023),
+		Gy:      big.NewInt(6036),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	// a Point of order 23
+	px, py := curve.ScalarBaseMult(big.NewInt(7889 / 23))
+	for want := int64(1); want < 23; want++ {
+		hx, hy := curve.ScalarMult(px, py, big.NewInt(want))
+		if k := curve.ShankWithN(px, py, hx, hy, big.NewInt(23)); k == nil || k.Int64() != want {
+			t.Errorf("want: %d, got: %d", want, k)
+		}
+	}
+
+	// an order too small for the Point
+	hx, hy := curve.ScalarMult(px, py, big.NewInt(20))
+	if k := curve.ShankWithN(px, py, hx, hy, big.NewInt(7)); k != nil {
+		t.Errorf("order 7: want: nil, got: %d", k)
+	}
+
+	// orders whose √ is beyond the table, or beyond an int
+	for _, order := range []*big.Int{
+		new(big.Int).Lsh(big.NewInt(1), 2*22+2),
+		new(big.Int).Lsh(big.NewInt(1), 200),
+	} {
+		if k := curve.ShankWithN(px, py, hx, hy, order); k != nil {
+			t.Errorf("order %d: want: nil, got: %d", order, k)
+		}
+	}
+
+	hx, hy = curve.ScalarBaseMult(big.NewInt(1234))
+	if k := curve.PohligHellman(curve.Gx, curve.Gy, hx, hy); k == nil || k.Int64() != 1234 {
+		t.Errorf("[PohligHellman] want: 1234, got: %d", k)
+	}
+	if curve.N.Int64() != 7889 {
+		t.Errorf("PohligHellman changed N to %d", curve.N)
+	}
+}
+
+func TestDiscreteLog(t *testing.T) {
+	// N = 7889 = 7³·23
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		Gx:      big.NewInt(4023),
+		Gy:      big.NewInt(6036),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	// a Point of order 23
+	px, py := curve.ScalarBaseMult(big.NewInt(7889 / 23))
+	hx, hy := curve.ScalarMult(px, py, big.NewInt(17))
+	for _, order := range []int64{23, 7889} {
+		k, err := curve.DiscreteLog(px, py, hx, hy, big.NewInt(order))
+		if err != nil {
+			t.Errorf("order %d: got error: %v", order, err)
+			continue
+		}
+		if new(big.Int).Mod(k, big.NewInt(23)).Int64() != 17 {
+			t.Errorf("order %d: want: 17 mod 23, got: %d", order, k)
+		}
+	}
+	if curve.N.Int64() != 7889 {
+		t.Errorf("DiscreteLog changed N to %d", curve.N)
+	}
+
+	if _, err := curve.DiscreteLog(px, py, hx, hy, big.NewInt(7)); err != ErrInvalidOrder {
+		t.Errorf("got: %v, want: %v", err, ErrInvalidOrder)
+	}
+	// G is not in the subgroup of order 23
+	if _, err := curve.DiscreteLog(px, py, curve.Gx, curve.Gy, big.NewInt(7889)); err != ErrNoDiscreteLog {
+		t.Errorf("got: %v, want: %v", err, ErrNoDiscreteLog)
+	}
+	if _, err := curve.DiscreteLog(px, new(big.Int).Add(py, big.NewInt(1)), hx, hy, big.NewInt(23)); err != ErrNotOnCurve {
+		t.Errorf("got: %v, want: %v", err, ErrNotOnCurve)
+	}
+	if k, err := curve.DiscreteLog(px, py, new(big.Int), new(big.Int), big.NewInt(23)); err != nil || k.Sign() != 0 {
+		t.Errorf("∞: got: %d, %v, want: 0", k, err)
+	}
+
+	// the whole group of COFACTOR, of order 10084 = 2²·2521, rather than
+	// the subgroup of order N = 2521
+	c := sampleCurves()["COFACTOR"]
+	order := new(big.Int).Mul(c.N, c.H)
+	for x := int64(1); x < 100; x++ {
+		y, _, ok := c.LiftX(big.NewInt(x))
+		if !ok {
+			continue
+		}
+		qx := big.NewInt(x)
+		for _, want := range []int64{1, 2, 3, 2521, 5000, 10083} {
+			hx, hy := c.ScalarMult(qx, y, big.NewInt(want))
+			k, err := c.DiscreteLog(qx, y, hx, hy, order)
+			if err != nil {
+				t.Errorf("(%d,%d), %d: got error: %v", qx, y, want, err)
+				continue
+			}
+			if kx, ky := c.ScalarMult(qx, y, k); kx.Cmp(hx) != 0 || ky.Cmp(hy) != 0 {
+				t.Errorf("(%d,%d): got: %d, want: %d", qx, y, k, want)
+			}
+		}
+	}
+}
+
+func TestPollardRhoParallel(t *testing.T) {
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		Gx:      big.NewInt(4023),
+		Gy:      big.NewInt(6036),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	px, py := curve.Gx, curve.Gy
+
+	rules := map[string]func(x, y *big.Int) bool{
+		"default": nil,
+		"x = 0 mod 8": func(x, y *big.Int) bool {
+			return x.Bit(0) == 0 && x.Bit(1) == 0 && x.Bit(2) == 0
+		},
+	}
+	for name, dp := range rules {
+		for _, want := range []*big.Int{big.NewInt(1), big.NewInt(1234), big.NewInt(7888)} {
+			hx, hy := curve.ScalarBaseMult(want)
+			k := curve.PollardRhoParallel(px, py, hx, hy, 4, dp)
+			if k == nil || k.Cmp(want) != 0 {
+				t.Errorf("[%s] (%d,%d) want: %d, got: %d", name, hx, hy, want, k)
+			}
+		}
+	}
+
+	// no Point is ever distinguished, so the step budget runs out
+	hx, hy := curve.ScalarBaseMult(big.NewInt(1234))
+	if k := curve.PollardRhoParallel(px, py, hx, hy, 4, func(x, y *big.Int) bool { return false }); k != nil {
+		t.Errorf("[never] want: nil, got: %d", k)
+	}
+}
+
+func TestPollardRhoParallelLargeOrder(t *testing.T) {
+	// N ≥ 2²⁵², where 20·2^(N.BitLen()/4) overflows an int64
+	for name, curve := range map[string]*Curve{"P-256": p256(), "S256": sampleCurves()["S256"]} {
+		hx, hy := curve.ScalarBaseMult(big.NewInt(123456789))
+		done := make(chan *big.Int)
+		go func() { done <- curve.pollardRhoParallel(curve.Gx, curve.Gy, hx, hy, 4, nil, 2000) }()
+		select {
+		case k := <-done:
+			if k != nil {
+				t.Errorf("[%s] want: nil, got: %d", name, k)
+			}
+		case <-time.After(30 * time.Second):
+			t.Fatalf("[%s] did not return", name)
+		}
+	}
+}
+
+func TestPollardRhoBrent(t *testing.T) {
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		Gx:      big.NewInt(4023),
+		Gy:      big.NewInt(6036),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	px, py := curve.Gx, curve.Gy
+
+	for _, want := range []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(1234), big.NewInt(5000), big.NewInt(7000)} {
+		hx, hy := curve.ScalarBaseMult(want)
+		if k := curve.PollardRhoBrent(px, py, hx, hy); k == nil || k.Cmp(want) != 0 {
+			t.Errorf("[PollardRhoBrent] (%d,%d) want: %d, got: %d", hx, hy, want, k)
+		}
+		if k := curve.PollardRho(px, py, hx, hy); k == nil || k.Cmp(want) != 0 {
+			t.Errorf("[PollardRho] (%d,%d) want: %d, got: %d", hx, hy, want, k)
+		}
+	}
+}
+
+func TestPollardRhoRand(t *testing.T) {
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		Gx:      big.NewInt(4023),
+		Gy:      big.NewInt(6036),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	px, py := curve.Gx, curve.Gy
+	hx, hy := curve.ScalarBaseMult(big.NewInt(1234))
+
+	for name, rho := range map[string]func(px, py, hx, hy *big.Int) (*big.Int, int){
+		"Floyd": curve.pollardRho,
+		"Brent": curve.pollardRhoBrent,
+	} {
+		curve.Rand = rand.New(rand.NewSource(1))
+		k1, n1 := rho(px, py, hx, hy)
+		curve.Rand = rand.New(rand.NewSource(1))
+		k2, n2 := rho(px, py, hx, hy)
+		if k1 == nil || k1.Int64() != 1234 {
+			t.Errorf("[%s] want: 1234, got: %d", name, k1)
+		}
+		if k1.Cmp(k2) != 0 || n1 != n2 {
+			t.Errorf("[%s] same Rand gave %d after %d steps, then %d after %d steps", name, k1, n1, k2, n2)
+		}
+	}
+}
+
+func BenchmarkPollardRhoCycleDetection(b *testing.B) {
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		Gx:      big.NewInt(4023),
+		Gy:      big.NewInt(6036),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	px, py := curve.Gx, curve.Gy
+	hx, hy := curve.ScalarBaseMult(big.NewInt(1234))
+
+	for name, rho := range map[string]func(px, py, hx, hy *big.Int) (*big.Int, int){
+		"Floyd": curve.pollardRho,
+		"Brent": curve.pollardRhoBrent,
+	} {
+		b.Run(name, func(b *testing.B) {
+			evals := 0
+			for i := 0; i < b.N; i++ {
+				_, n := rho(px, py, hx, hy)
+				evals += n
+			}
+			b.ReportMetric(float64(evals)/float64(b.N), "evals/op")
+		})
+	}
+}
+
+func TestFactorize(t *testing.T) {
+	// 2^5·3^3·5^2·7·11·13^2·101·9973·10007·1000003·(2^61 - 1)
+	want := []int64{2, 2, 2, 2, 2, 3, 3, 3, 5, 5, 7, 11, 13, 13, 101, 9973, 10007, 1000003, 1<<61 - 1}
+	n := big.NewInt(1)
+	for _, f := range want {
+		n.Mul(n, big.NewInt(f))
+	}
+
+	// the sieve stops at √n, so a huge bound costs nothing
+	for _, bound := range []int64{0, 100, DefaultTrialBound, 1 << 20, 1 << 40, math.MaxInt64} {
+		got, err := FactorizeWithBound(n, bound)
+		if err != nil {
+			t.Errorf("bound %d: %v", bound, err)
+			continue
+		}
+		if len(got) != len(want) {
+			t.Errorf("bound %d got: %v, want: %v", bound, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i].Int64() != want[i] {
+				t.Errorf("bound %d got: %v, want: %v", bound, got, want)
+				break
+			}
+		}
+	}
+
+	forty2s := make([]int64, 40)
+	for i := range forty2s {
+		forty2s[i] = 2
+	}
+	for _, c := range []struct {
+		n    int64
+		want []int64
+	}{
+		{0, nil},
+		{1, nil},
+		{2, []int64{2}},
+		{9973, []int64{9973}},
+		{10007 * 10007, []int64{10007, 10007}},
+		{1 << 40, forty2s},
+	} {
+		got, err := Factorize(big.NewInt(c.n))
+		if err != nil {
+			t.Errorf("%d: %v", c.n, err)
+			continue
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%d got: %v, want: %v", c.n, got, c.want)
+			continue
+		}
+		for i := range c.want {
+			if got[i].Int64() != c.want[i] {
+				t.Errorf("%d got: %v, want: %v", c.n, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFactorizeHugeBound(t *testing.T) {
+	cases := []struct {
+		n    int64
+		want []int64
+	}{
+		{12, []int64{2, 2, 3}},
+		{2 * (1<<61 - 1), []int64{2, 1<<61 - 1}},
+		{3 * 5 * 1000003, []int64{3, 5, 1000003}},
+	}
+	for _, c := range cases {
+		got, err := FactorizeWithBound(big.NewInt(c.n), math.MaxInt64)
+		if err != nil || len(got) != len(c.want) {
+			t.Errorf("%d got: %v, %v, want: %v", c.n, got, err, c.want)
+			continue
+		}
+		for i := range c.want {
+			if got[i].Int64() != c.want[i] {
+				t.Errorf("%d got: %v, want: %v", c.n, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFactorizeUnsplit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("rho gives up only after a long walk")
+	}
+	// two 64-bit primes are beyond rho's walk
+	p, _ := new(big.Int).SetString("18446744073709551557", 10)
+	q, _ := new(big.Int).SetString("18446744073709551533", 10)
+	n := new(big.Int).Mul(p, q)
+	got, err := FactorizeWithBound(new(big.Int).Lsh(n, 1), 100)
+	if err != ErrFactorization || len(got) != 2 || got[0].Int64() != 2 || got[1].Cmp(n) != 0 {
+		t.Errorf("got: %v, %v, want: [2 %d], %v", got, err, n, ErrFactorization)
+	}
+}
+
+func TestForEachPrime(t *testing.T) {
+	// against trial division, across several sieve segments
+	isPrime := func(n int64) bool {
+		for d := int64(2); d*d <= n; d++ {
+			if n%d == 0 {
+				return false
+			}
+		}
+		return n >= 2
+	}
+	// primeSegment + 3 ends on a block shorter than most of the sieving primes
+	for _, bound := range []int64{-1, 0, 1, 2, 3, 100, primeSegment - 1, primeSegment, primeSegment + 3, 3*primeSegment + 7} {
+		var got []int64
+		forEachPrime(bound, func(p int64) bool {
+			got = append(got, p)
+			return true
+		})
+		var want []int64
+		for n := int64(2); n <= bound; n++ {
+			if isPrime(n) {
+				want = append(want, n)
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("bound %d: got %d primes, want: %d", bound, len(got), len(want))
+		}
+	}
+
+	// f returning false stops the walk
+	var got []int64
+	forEachPrime(1<<40, func(p int64) bool {
+		got = append(got, p)
+		return p < 7
+	})
+	if !reflect.DeepEqual(got, []int64{2, 3, 5, 7}) {
+		t.Errorf("got: %v, want: [2 3 5 7]", got)
+	}
+}
+
+func BenchmarkFactorize(b *testing.B) {
+	// 2^5·3^3·5^2·7·11·13^2·101·9973·10007·1000003
+	n := BigFromDecimal("2833241381987255665826400")
+	for _, bound := range []int64{0, DefaultTrialBound} {
+		b.Run(fmt.Sprint(bound), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := FactorizeWithBound(n, bound); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
 }
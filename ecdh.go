@@ -0,0 +1,73 @@
+package ecc
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrSmallOrder     = errors.New("ecc: point of small order")
+	ErrSharedInfinity = errors.New("ecc: shared secret is the point at infinity")
+)
+
+// ECDH performs an elliptic curve Diffie-Hellman key agreement with the
+// private key priv and the peer's public key (x, y). It returns the
+// x-coordinate of the shared point computed by DHRaw, padded to the field
+// size.
+func (c *Curve) ECDH(priv, x, y *big.Int) ([]byte, error) {
+	sx, _, err := c.DHRaw(priv, x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]byte, (c.BitSize+7)/8)
+	sx.FillBytes(ret)
+	return ret, nil
+}
+
+// DHRaw returns the whole shared point scalar·(peerX, peerY) of a
+// Diffie-Hellman key agreement, for protocols which need its y-coordinate
+// too.
+//
+// A peer may send a point on a different curve over the same field, whose
+// group order has small factors, to learn scalar modulo those factors (the
+// invalid-curve attack). Such points are rejected because they are not on c.
+// When the cofactor H is greater than one, points on c whose order divides H
+// are rejected as well.
+func (c *Curve) DHRaw(scalar, peerX, peerY *big.Int) (*big.Int, *big.Int, error) {
+	if !c.IsOnCurve(peerX, peerY) {
+		return nil, nil, ErrNotOnCurve
+	}
+	if c.H != nil && c.H.Cmp(big.NewInt(1)) > 0 {
+		hx, hy := c.ScalarMult(peerX, peerY, c.H)
+		if IsInfinity(hx, hy) {
+			return nil, nil, ErrSmallOrder
+		}
+	}
+
+	sx, sy := c.ScalarMult(peerX, peerY, scalar)
+	if IsInfinity(sx, sy) {
+		return nil, nil, ErrSharedInfinity
+	}
+	return sx, sy, nil
+}
+
+// ClampScalar returns a copy of the 32-byte little-endian scalar b clamped
+// as in X25519 (RFC 7748): the low three bits are cleared, so the scalar is
+// a multiple of the cofactor 8 of Curve25519 and Ed25519 and kills any
+// small-order component of the peer's point, and bit 255 is cleared and bit
+// 254 set, so every scalar has the same length and a ladder over it runs in
+// the same time. It applies only to those curves, whose scalars are taken
+// as 32 random bytes, and to EdDSA secret scalars derived from a hash; the
+// short Weierstrass curves of this package use scalars reduced modulo N
+// instead. It panics if b is not 32 bytes long.
+func ClampScalar(b []byte) []byte {
+	if len(b) != 32 {
+		panic("ecc: scalar to clamp is not 32 bytes")
+	}
+	k := append([]byte(nil), b...)
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+	return k
+}
@@ -0,0 +1,134 @@
+package ecc
+
+import (
+	"bytes"
+	stdecdh "crypto/ecdh"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestECDH(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv1, x1, y1, _ := curve.GenerateKey(rand.Reader)
+		priv2, x2, y2, _ := curve.GenerateKey(rand.Reader)
+
+		s1, err := curve.ECDH(priv1, x2, y2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s2, err := curve.ECDH(priv2, x1, y1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(s1, s2) {
+			t.Errorf("shared secrets differ: %x, %x", s1, s2)
+		}
+	})
+}
+
+func TestDHRaw(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv1, x1, y1, _ := curve.GenerateKey(rand.Reader)
+		priv2, x2, y2, _ := curve.GenerateKey(rand.Reader)
+
+		sx1, sy1, err := curve.DHRaw(priv1, x2, y2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sx2, sy2, err := curve.DHRaw(priv2, x1, y1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sx1.Cmp(sx2) != 0 || sy1.Cmp(sy2) != 0 {
+			t.Errorf("shared points differ: (%d,%d), (%d,%d)", sx1, sy1, sx2, sy2)
+		}
+		// (priv1·priv2)·G
+		k := new(big.Int).Mul(priv1, priv2)
+		if x, y := curve.ScalarBaseMult(k); x.Cmp(sx1) != 0 || y.Cmp(sy1) != 0 {
+			t.Errorf("got: (%d,%d), want: (%d,%d)", sx1, sy1, x, y)
+		}
+
+		if _, _, err := curve.DHRaw(priv1, x2, new(big.Int).Add(y2, big.NewInt(1))); err != ErrNotOnCurve {
+			t.Errorf("got: %v, want: %v", err, ErrNotOnCurve)
+		}
+		if _, _, err := curve.DHRaw(curve.N, x2, y2); err != ErrSharedInfinity {
+			t.Errorf("got: %v, want: %v", err, ErrSharedInfinity)
+		}
+	})
+}
+
+func TestECDHInvalidCurve(t *testing.T) {
+	curve := sampleCurves()["TOY"]
+	priv, _, _, _ := curve.GenerateKey(rand.Reader)
+
+	// (3, 10) has order 3 on y² = x³ + 4x + 3 over F_29, which shares the
+	// field and A with TOY.
+	if _, err := curve.ECDH(priv, big.NewInt(3), big.NewInt(10)); err != ErrNotOnCurve {
+		t.Errorf("got: %v, want: %v", err, ErrNotOnCurve)
+	}
+}
+
+func TestECDHSmallOrder(t *testing.T) {
+	// #E = 80, base point of order 5
+	curve := &Curve{
+		P:       big.NewInt(97),
+		A:       big.NewInt(46),
+		B:       big.NewInt(74),
+		Gx:      big.NewInt(49),
+		Gy:      big.NewInt(45),
+		N:       big.NewInt(5),
+		H:       big.NewInt(16),
+		BitSize: 7,
+	}
+	priv, x, y, _ := curve.GenerateKey(rand.Reader)
+	if _, err := curve.ECDH(priv, x, y); err != nil {
+		t.Errorf("got error: %v", err)
+	}
+
+	// (57, 0) has order 2
+	if _, err := curve.ECDH(priv, big.NewInt(57), big.NewInt(0)); err != ErrSmallOrder {
+		t.Errorf("got: %v, want: %v", err, ErrSmallOrder)
+	}
+}
+
+func TestClampScalar(t *testing.T) {
+	eight := big.NewInt(8)
+	for i := 0; i < 100; i++ {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			t.Fatal(err)
+		}
+		orig := append([]byte(nil), b...)
+		k := ClampScalar(b)
+		if !bytes.Equal(b, orig) {
+			t.Fatal("input modified")
+		}
+		if again := ClampScalar(k); !bytes.Equal(again, k) {
+			t.Errorf("not idempotent: got: %x, want: %x", again, k)
+		}
+
+		// as an integer, 2²⁵⁴ <= k < 2²⁵⁵ and k ≡ 0 (mod 8)
+		le := make([]byte, 32)
+		for j := range k {
+			le[31-j] = k[j]
+		}
+		n := new(big.Int).SetBytes(le)
+		if n.BitLen() != 255 || new(big.Int).Mod(n, eight).Sign() != 0 {
+			t.Errorf("got: %d", n)
+		}
+
+		// crypto/ecdh clamps internally, so both give the same public key
+		p1, err := stdecdh.X25519().NewPrivateKey(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p2, err := stdecdh.X25519().NewPrivateKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !p1.PublicKey().Equal(p2.PublicKey()) {
+			t.Errorf("X25519 public keys differ for %x", b)
+		}
+	}
+}
@@ -1,16 +1,74 @@
 package ecc
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"hash"
+	"io"
 	"math/big"
 )
 
+var (
+	ErrInvalidPrivateKey = errors.New("ecc: invalid private key")
+	ErrInvalidSignature  = errors.New("ecc: invalid signature encoding")
+	ErrInvalidPublicKey  = errors.New("ecc: invalid public key")
+	ErrHashUnavailable   = errors.New("ecc: hash function is not available")
+	ErrDigestLength      = errors.New("ecc: digest length does not match the hash")
+	ErrKeyMismatch       = errors.New("ecc: public key does not match the private key")
+
+	ErrSignatureRange    = errors.New("ecc: r or s is not in [1, N-1]")
+	ErrSNotInvertible    = errors.New("ecc: s is not invertible modulo N")
+	ErrSignatureMismatch = errors.New("ecc: signature does not match the hash and public key")
+)
+
+// MarshalPrivateKey converts a private key into a big-endian byte slice padded
+// to OrderBytes.
+func (c *Curve) MarshalPrivateKey(priv *big.Int) []byte {
+	ret := make([]byte, c.OrderBytes())
+	priv.FillBytes(ret)
+	return ret
+}
+
+// UnmarshalPrivateKey converts a private key, serialized by MarshalPrivateKey,
+// into an integer. It is an error if the length is not OrderBytes or the key
+// is not in [1, N-1].
+func (c *Curve) UnmarshalPrivateKey(b []byte) (*big.Int, error) {
+	if len(b) != c.OrderBytes() {
+		return nil, ErrInvalidPrivateKey
+	}
+	priv := new(big.Int).SetBytes(b)
+	if priv.Sign() == 0 || priv.Cmp(c.N) >= 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+	return priv, nil
+}
+
+// CheckKeyPair checks that (pubX, pubY) is priv·G. It returns
+// ErrInvalidPrivateKey if priv is not in [1, N-1], and ErrKeyMismatch if the
+// public key is anything else.
+func (c *Curve) CheckKeyPair(priv, pubX, pubY *big.Int) error {
+	if priv == nil || priv.Sign() <= 0 || priv.Cmp(c.N) >= 0 {
+		return ErrInvalidPrivateKey
+	}
+	if pubX == nil || pubY == nil {
+		return ErrKeyMismatch
+	}
+	x, y := c.ScalarBaseMult(priv)
+	if x.Cmp(pubX) != 0 || y.Cmp(pubY) != 0 {
+		return ErrKeyMismatch
+	}
+	return nil
+}
+
 // hashToInt converts a hash value to an integer. Per FIPS 186-4, Section 6.4,
 // we use the left-most bits of the hash to match the bit-length of the order of
 // the curve. This also performs Step 5 of SEC 1, Version 2.0, Section 4.1.3.
 func (c *Curve) hashToInt(hash []byte) *big.Int {
 	orderBits := c.N.BitLen()
-	orderBytes := (orderBits + 7) / 8
+	orderBytes := c.OrderBytes()
 	if len(hash) > orderBytes {
 		hash = hash[:orderBytes]
 	}
@@ -27,37 +85,144 @@ func (c *Curve) hashToInt(hash []byte) *big.Int {
 // using the private key, priv. If the hash is longer than the bit-length of the
 // private key's curve order, the hash will be truncated to that length. It
 // returns the signature as a pair of integers.
+//
+// The order N of the base Point must be prime, since k is inverted with
+// FermatInverse. Sign panics if the curve has no base Point; SignASN1 and
+// SignHash return ErrNoBasePoint instead.
 func (c *Curve) Sign(priv *big.Int, hash []byte) (r, s *big.Int) {
-	N := c.N
-	var k *big.Int
+	if !c.HasBasePoint() {
+		panic(ErrNoBasePoint.Error())
+	}
+	r, s, err := c.signRandom(rand.Reader, priv, hash)
+	if err != nil {
+		panic("ecc: internal error: " + err.Error())
+	}
+	return r, s
+}
+
+// signRandom signs hash with priv, drawing nonces from rnd until one gives a
+// valid signature. It only fails if rnd does or the curve has no base Point.
+func (c *Curve) signRandom(rnd io.Reader, priv *big.Int, hash []byte) (r, s *big.Int, err error) {
+	if !c.HasBasePoint() {
+		return nil, nil, ErrNoBasePoint
+	}
+	nMinus1 := new(big.Int).Sub(c.N, big.NewInt(1))
 	for {
-		k, r, _, _ = c.GenerateKey(rand.Reader)
-		r.Mod(r, N)
-		if r.Sign() == 0 {
-			continue
+		k, err := rand.Int(rnd, nMinus1)
+		if err != nil {
+			return nil, nil, err
+		}
+		k.Add(k, big.NewInt(1))
+		if r, s = c.signWithNonce(priv, k, hash); r != nil {
+			return r, s, nil
 		}
-		inv := FermatInverse(k, N)
+	}
+}
 
-		z := c.hashToInt(hash)
-		s = new(big.Int).Set(priv)
-		s.Mul(s, r)
-		s.Add(s, z)
-		s.Mul(s, inv)
-		s.Mod(s, N)
-		if s.Sign() != 0 {
-			return
+// signWithNonce returns the signature of hash with priv and the nonce k, or
+// nil if k gives r = 0 or s = 0.
+func (c *Curve) signWithNonce(priv, k *big.Int, hash []byte) (r, s *big.Int) {
+	N := c.N
+	r, _ = c.ScalarBaseMult(k)
+	r.Mod(r, N)
+	if r.Sign() == 0 {
+		return nil, nil
+	}
+	inv := FermatInverse(k, N)
+
+	z := c.hashToInt(hash)
+	s = new(big.Int).Set(priv)
+	s.Mul(s, r)
+	s.Add(s, z)
+	s.Mul(s, inv)
+	s.Mod(s, N)
+	if s.Sign() == 0 {
+		return nil, nil
+	}
+	return r, s
+}
+
+// SignHash signs digest, the result of hashing a message with hashID, using
+// the private key priv. The nonce is derived deterministically from priv and
+// digest as in RFC 6979, with HMAC over hashID, so the same inputs always give
+// the same signature. It is an error if hashID is not linked into the binary,
+// digest is not hashID.Size() bytes long, or the curve has no base Point.
+func (c *Curve) SignHash(priv *big.Int, digest []byte, hashID crypto.Hash) (r, s *big.Int, err error) {
+	if !c.HasBasePoint() {
+		return nil, nil, ErrNoBasePoint
+	}
+	if !hashID.Available() {
+		return nil, nil, ErrHashUnavailable
+	}
+	if len(digest) != hashID.Size() {
+		return nil, nil, ErrDigestLength
+	}
+
+	nonce := c.nonceRFC6979(priv, digest, hashID.New)
+	for {
+		if r, s = c.signWithNonce(priv, nonce(), digest); r != nil {
+			return r, s, nil
 		}
 	}
 }
 
 // Verify verifies the signature in r, s of hash using the public key, pub.
+// It reports whether VerifyDiagnostic finds no fault.
 func (c *Curve) Verify(hx, hy *big.Int, hash []byte, r, s *big.Int) bool {
+	return c.VerifyDiagnostic(hx, hy, hash, r, s) == nil
+}
+
+// VerifyDiagnostic is like Verify, but returns why a signature is rejected:
+// ErrSignatureRange if r or s is not in [1, N-1], ErrInvalidPublicKey if the
+// public key is the Point at infinity, ErrNotOnCurve if it is not on the
+// curve (unless SkipValidation is set), ErrSNotInvertible if s and N are not
+// coprime, which only happens when N is not prime, and ErrSignatureMismatch
+// if the x-coordinate of u1·G + u2·Q is not r modulo N. It returns nil if
+// the signature is valid. It returns ErrNoBasePoint if the curve has none.
+func (c *Curve) VerifyDiagnostic(hx, hy *big.Int, hash []byte, r, s *big.Int) error {
+	if !c.HasBasePoint() {
+		return ErrNoBasePoint
+	}
+	N := c.N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(N) >= 0 || s.Cmp(N) >= 0 {
+		return ErrSignatureRange
+	}
+	if IsInfinity(hx, hy) {
+		return ErrInvalidPublicKey
+	}
+	if !c.SkipValidation && !c.IsOnCurve(hx, hy) {
+		return ErrNotOnCurve
+	}
+	if new(big.Int).GCD(nil, nil, s, N).Cmp(big.NewInt(1)) != 0 {
+		return ErrSNotInvertible
+	}
+	if ok, _, _ := c.VerifyReturningR(hx, hy, hash, r, s); !ok {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// VerifyCompressed verifies the signature in r, s of hash using the public key
+// in the compressed form of MarshalCompressed. It is an error if the public
+// key can't be decompressed.
+func (c *Curve) VerifyCompressed(pubCompressed []byte, hash []byte, r, s *big.Int) (bool, error) {
+	x, y := c.UnmarshalCompressed(pubCompressed)
+	if x == nil || IsInfinity(x, y) {
+		return false, ErrInvalidPublicKey
+	}
+	return c.Verify(x, y, hash, r, s), nil
+}
+
+// VerifyReturningR is like Verify, but also returns the Point R = u1·G + u2·Q
+// it reconstructs, whose x-coordinate reduced mod N is compared to r. R is nil
+// if r or s is out of range.
+func (c *Curve) VerifyReturningR(hx, hy *big.Int, hash []byte, r, s *big.Int) (ok bool, Rx, Ry *big.Int) {
 	N := c.N
 	if r.Sign() <= 0 || s.Sign() <= 0 {
-		return false
+		return false, nil, nil
 	}
 	if r.Cmp(N) >= 0 || s.Cmp(N) >= 0 {
-		return false
+		return false, nil, nil
 	}
 
 	u1 := c.hashToInt(hash)
@@ -67,10 +232,128 @@ func (c *Curve) Verify(hx, hy *big.Int, hash []byte, r, s *big.Int) bool {
 	u2.Mul(u2, r)
 	u2.Mod(u2, N)
 
-	x, y := c.CombinedMult(hx, hy, u1, u2)
-	if x.Sign() == 0 && y.Sign() == 0 {
+	Rx, Ry = c.CombinedMult(hx, hy, u1, u2)
+	if IsInfinity(Rx, Ry) {
+		return false, Rx, Ry
+	}
+	x := new(big.Int).Mod(Rx, N)
+	return x.Cmp(r) == 0, Rx, Ry
+}
+
+// taggedHash returns h(h(context) || h(context) || data...), so that digests
+// computed under different contexts never collide.
+func taggedHash(h func() hash.Hash, context []byte, data ...[]byte) []byte {
+	d := h()
+	d.Write(context)
+	tag := d.Sum(nil)
+
+	d.Reset()
+	d.Write(tag)
+	d.Write(tag)
+	for _, b := range data {
+		d.Write(b)
+	}
+	return d.Sum(nil)
+}
+
+// SignWithContext signs hash like Sign, but binds the signature to context.
+// The hash is replaced by a tagged hash of context and hash, and the nonce is
+// derived from a tagged hash of the private key, the message and fresh
+// randomness, so a signature made under one context is never valid under
+// another. It panics if the curve has no base Point.
+func (c *Curve) SignWithContext(priv *big.Int, hash, context []byte, h func() hash.Hash) (r, s *big.Int) {
+	if !c.HasBasePoint() {
+		panic(ErrNoBasePoint.Error())
+	}
+	N := c.N
+	msg := taggedHash(h, context, hash)
+	z := c.hashToInt(msg)
+	privBytes := c.MarshalPrivateKey(priv)
+
+	nMinus1 := new(big.Int).Sub(N, big.NewInt(1))
+	entropy := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(rand.Reader, entropy); err != nil {
+			panic("ecc: failed to read randomness")
+		}
+
+		// expand to 64 extra bits to make the bias mod N negligible
+		var buf []byte
+		for i := byte(0); len(buf) < c.OrderBytes()+8; i++ {
+			buf = append(buf, taggedHash(h, context, []byte{i}, privBytes, msg, entropy)...)
+		}
+		k := new(big.Int).SetBytes(buf)
+		k.Mod(k, nMinus1)
+		k.Add(k, big.NewInt(1))
+
+		r, _ = c.ScalarBaseMult(k)
+		r.Mod(r, N)
+		if r.Sign() == 0 {
+			continue
+		}
+
+		s = new(big.Int).Mul(priv, r)
+		s.Add(s, z)
+		s.Mul(s, FermatInverse(k, N))
+		s.Mod(s, N)
+		if s.Sign() != 0 {
+			return
+		}
+	}
+}
+
+// VerifyWithContext verifies a signature made by SignWithContext.
+func (c *Curve) VerifyWithContext(hx, hy *big.Int, hash, context []byte, h func() hash.Hash, r, s *big.Int) bool {
+	return c.Verify(hx, hy, taggedHash(h, context, hash), r, s)
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// EncodeSignatureDER encodes the signature (r, s) as an ASN.1 DER SEQUENCE of
+// two INTEGERs, as used by X.509 and crypto/ecdsa.
+func EncodeSignatureDER(r, s *big.Int) ([]byte, error) {
+	return asn1.Marshal(ecdsaSignature{r, s})
+}
+
+// DecodeSignatureDER decodes a signature encoded by EncodeSignatureDER. It is
+// an error if the encoding is not the unique DER encoding of (r, s), or r or s
+// is not positive.
+func DecodeSignatureDER(b []byte) (r, s *big.Int, err error) {
+	var sig ecdsaSignature
+	rest, err := asn1.Unmarshal(b, &sig)
+	if err != nil || len(rest) != 0 {
+		return nil, nil, ErrInvalidSignature
+	}
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 {
+		return nil, nil, ErrInvalidSignature
+	}
+	// encoding/asn1 ignores trailing elements of the SEQUENCE
+	if der, err := EncodeSignatureDER(sig.R, sig.S); err != nil || !bytes.Equal(der, b) {
+		return nil, nil, ErrInvalidSignature
+	}
+	return sig.R, sig.S, nil
+}
+
+// SignASN1 signs hash like Sign, with nonces drawn from rand, and returns the
+// signature in the DER encoding of EncodeSignatureDER, like
+// crypto/ecdsa.SignASN1.
+func (c *Curve) SignASN1(rand io.Reader, priv *big.Int, hash []byte) ([]byte, error) {
+	r, s, err := c.signRandom(rand, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeSignatureDER(r, s)
+}
+
+// VerifyASN1 verifies the DER-encoded signature sig of hash using the public
+// key (pubX, pubY), like crypto/ecdsa.VerifyASN1. A signature that is not in
+// the strict encoding of DecodeSignatureDER is invalid.
+func (c *Curve) VerifyASN1(pubX, pubY *big.Int, hash, sig []byte) bool {
+	r, s, err := DecodeSignatureDER(sig)
+	if err != nil {
 		return false
 	}
-	x.Mod(x, N)
-	return x.Cmp(r) == 0
+	return c.Verify(pubX, pubY, hash, r, s)
 }
@@ -1,7 +1,14 @@
 package ecc
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
 	"testing"
 )
 
@@ -21,6 +28,215 @@ func TestSignAndVerify(t *testing.T) {
 	})
 }
 
+func TestHashToInt(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, pubX, pubY, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		orderBits := curve.N.BitLen()
+		ob := curve.OrderBytes()
+		for _, n := range []int{0, 1, ob - 1, ob, ob + 1, 64} {
+			hash := make([]byte, n)
+			if _, err := rand.Read(hash); err != nil {
+				t.Fatal(err)
+			}
+
+			// the left-most orderBits bits of hash
+			want := new(big.Int).SetBytes(hash)
+			if excess := n*8 - orderBits; excess > 0 {
+				want.Rsh(want, uint(excess))
+			}
+			if got := curve.hashToInt(hash); got.Cmp(want) != 0 {
+				t.Errorf("len %d: got: %x, want: %x", n, got, want)
+			}
+
+			if n == 0 {
+				continue
+			}
+			r, s := curve.Sign(priv, hash)
+			if !curve.Verify(pubX, pubY, hash, r, s) {
+				t.Errorf("len %d: Verify failed", n)
+			}
+		}
+	})
+
+	// N = 37 has 6 bits
+	toy := sampleCurves()["TOY"]
+	cases := []struct {
+		hash []byte
+		ans  int64
+	}{
+		{[]byte{}, 0},
+		{[]byte{0xff}, 63},
+		{[]byte{0x84, 0xff}, 33},
+	}
+	for _, c := range cases {
+		if got := toy.hashToInt(c.hash); got.Int64() != c.ans {
+			t.Errorf("%x: got: %d, want: %d", c.hash, got, c.ans)
+		}
+	}
+}
+
+func TestSignWithContext(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, pubX, pubY, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed := []byte("testing")
+		ctx1, ctx2 := []byte("protocol-1"), []byte("protocol-2")
+
+		r, s := curve.SignWithContext(priv, hashed, ctx1, sha256.New)
+		if !curve.VerifyWithContext(pubX, pubY, hashed, ctx1, sha256.New, r, s) {
+			t.Errorf("Verify failed")
+		}
+		if curve.N.BitLen() < 32 {
+			// collisions of z mod N are likely on toy curves
+			return
+		}
+		if curve.VerifyWithContext(pubX, pubY, hashed, ctx2, sha256.New, r, s) {
+			t.Errorf("signature verified under another context")
+		}
+		if curve.Verify(pubX, pubY, hashed, r, s) {
+			t.Errorf("signature verified without context")
+		}
+	})
+}
+
+func TestVerifyReturningR(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, pubX, pubY, _ := curve.GenerateKey(rand.Reader)
+		hashed := []byte("testing")
+		r, s := curve.Sign(priv, hashed)
+		ok, Rx, Ry := curve.VerifyReturningR(pubX, pubY, hashed, r, s)
+		if !ok {
+			t.Fatal("Verify failed")
+		}
+		if !curve.IsOnCurve(Rx, Ry) {
+			t.Errorf("R is not on the curve")
+		}
+		if x := new(big.Int).Mod(Rx, curve.N); x.Cmp(r) != 0 {
+			t.Errorf("Rx mod N = %d, want: %d", x, r)
+		}
+	})
+}
+
+func TestVerifyDiagnostic(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, x, y, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := sha256.Sum256([]byte("testing"))
+		r, s := curve.Sign(priv, hash[:])
+		if err := curve.VerifyDiagnostic(x, y, hash[:], r, s); err != nil {
+			t.Errorf("valid signature: got: %v", err)
+		}
+
+		// only one of y+1 and y+2 can be -y
+		offY := new(big.Int).Add(y, big.NewInt(1))
+		if curve.IsOnCurve(x, offY) {
+			offY.Add(offY, big.NewInt(1))
+		}
+		other := sha256.Sum256([]byte("other"))
+		wantErr := ErrSignatureMismatch
+		if curve.Verify(x, y, other[:], r, s) {
+			// a chance collision on the small curves
+			wantErr = nil
+		}
+		for _, c := range []struct {
+			x, y, r, s *big.Int
+			hash       []byte
+			want       error
+		}{
+			{x, y, new(big.Int), s, hash[:], ErrSignatureRange},
+			{x, y, r, curve.N, hash[:], ErrSignatureRange},
+			{x, y, new(big.Int).Neg(r), s, hash[:], ErrSignatureRange},
+			{new(big.Int), new(big.Int), r, s, hash[:], ErrInvalidPublicKey},
+			{x, offY, r, s, hash[:], ErrNotOnCurve},
+			{x, y, r, s, other[:], wantErr},
+		} {
+			if err := curve.VerifyDiagnostic(c.x, c.y, c.hash, c.r, c.s); err != c.want {
+				t.Errorf("got: %v, want: %v", err, c.want)
+			}
+			if ok := curve.Verify(c.x, c.y, c.hash, c.r, c.s); ok != (c.want == nil) {
+				t.Errorf("Verify got: %v, want: %v", ok, c.want == nil)
+			}
+		}
+	})
+
+	// N = 7889 = 7³·23, so s = 7 has no inverse
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		Gx:      big.NewInt(4023),
+		Gy:      big.NewInt(6036),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	if err := curve.VerifyDiagnostic(curve.Gx, curve.Gy, []byte{1}, big.NewInt(5), big.NewInt(7)); err != ErrSNotInvertible {
+		t.Errorf("got: %v, want: %v", err, ErrSNotInvertible)
+	}
+}
+
+func TestNoBasePoint(t *testing.T) {
+	// as given to Schoof: no Gx and Gy
+	curve := &Curve{
+		P:       big.NewInt(7919),
+		A:       big.NewInt(1001),
+		B:       big.NewInt(75),
+		N:       big.NewInt(7889),
+		BitSize: 13,
+	}
+	if curve.HasBasePoint() {
+		t.Fatal("HasBasePoint is true")
+	}
+	priv := big.NewInt(1234)
+	hash := sha256.Sum256([]byte("testing"))
+
+	if _, _, _, err := curve.GenerateKey(rand.Reader); err != ErrNoBasePoint {
+		t.Errorf("GenerateKey: got: %v, want: %v", err, ErrNoBasePoint)
+	}
+	if _, err := curve.SignASN1(rand.Reader, priv, hash[:]); err != ErrNoBasePoint {
+		t.Errorf("SignASN1: got: %v, want: %v", err, ErrNoBasePoint)
+	}
+	if _, _, err := curve.SignHash(priv, hash[:], crypto.SHA256); err != ErrNoBasePoint {
+		t.Errorf("SignHash: got: %v, want: %v", err, ErrNoBasePoint)
+	}
+	if err := curve.VerifyDiagnostic(big.NewInt(1), big.NewInt(1), hash[:], big.NewInt(1), big.NewInt(1)); err != ErrNoBasePoint {
+		t.Errorf("VerifyDiagnostic: got: %v, want: %v", err, ErrNoBasePoint)
+	}
+
+	defer func() {
+		if r := recover(); r != ErrNoBasePoint.Error() {
+			t.Errorf("Sign: got panic: %v, want: %v", r, ErrNoBasePoint)
+		}
+	}()
+	curve.Sign(priv, hash[:])
+}
+
+func TestVerifyCompressed(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, pubX, pubY, _ := curve.GenerateKey(rand.Reader)
+		hashed := []byte("testing")
+		r, s := curve.Sign(priv, hashed)
+
+		ok, err := curve.VerifyCompressed(curve.MarshalCompressed(pubX, pubY), hashed, r, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Errorf("Verify failed")
+		}
+
+		if _, err := curve.VerifyCompressed([]byte{0x02}, hashed, r, s); err != ErrInvalidPublicKey {
+			t.Errorf("got: %v, want: %v", err, ErrInvalidPublicKey)
+		}
+	})
+}
+
 func BenchmarkSignAndVerify(b *testing.B) {
 	benchmarkAllCurves(b, func(b *testing.B, curve *Curve) {
 		priv, pubX, pubY, err := curve.GenerateKey(rand.Reader)
@@ -39,3 +255,227 @@ func BenchmarkSignAndVerify(b *testing.B) {
 		}
 	})
 }
+
+func TestMarshalPrivateKey(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, _, _, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := curve.MarshalPrivateKey(priv)
+		if len(b) != curve.OrderBytes() {
+			t.Errorf("got length %d, want %d", len(b), curve.OrderBytes())
+		}
+		k, err := curve.UnmarshalPrivateKey(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.Cmp(priv) != 0 {
+			t.Errorf("got: %d, want: %d", k, priv)
+		}
+	})
+}
+
+func TestUnmarshalInvalidPrivateKey(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		cases := map[string][]byte{
+			"zero":  make([]byte, curve.OrderBytes()),
+			"N":     curve.MarshalPrivateKey(curve.N),
+			"short": make([]byte, curve.OrderBytes()-1),
+			"long":  append([]byte{0}, curve.MarshalPrivateKey(big.NewInt(1))...),
+		}
+		over := make([]byte, curve.OrderBytes())
+		for i := range over {
+			over[i] = 0xff
+		}
+		cases["over-range"] = over
+
+		for name, b := range cases {
+			if _, err := curve.UnmarshalPrivateKey(b); err == nil {
+				t.Errorf("%s: UnmarshalPrivateKey succeeded", name)
+			}
+		}
+	})
+}
+
+func TestCheckKeyPair(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, x, y, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := curve.CheckKeyPair(priv, x, y); err != nil {
+			t.Errorf("matching pair: got: %v", err)
+		}
+
+		other := new(big.Int).Add(priv, big.NewInt(1))
+		other.Mod(other, curve.N)
+		if other.Sign() == 0 {
+			other.SetInt64(1)
+		}
+		if err := curve.CheckKeyPair(other, x, y); err != ErrKeyMismatch {
+			t.Errorf("mismatched pair: got: %v, want: %v", err, ErrKeyMismatch)
+		}
+		if nx, ny := curve.Neg(x, y); curve.CheckKeyPair(priv, nx, ny) != ErrKeyMismatch {
+			t.Error("negated public key accepted")
+		}
+		for _, k := range []*big.Int{big.NewInt(0), curve.N, new(big.Int).Neg(priv)} {
+			if err := curve.CheckKeyPair(k, x, y); err != ErrInvalidPrivateKey {
+				t.Errorf("priv = %d: got: %v, want: %v", k, err, ErrInvalidPrivateKey)
+			}
+		}
+	})
+}
+
+func TestSignatureDER(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, _, _, _ := curve.GenerateKey(rand.Reader)
+		r, s := curve.Sign(priv, []byte("testing"))
+		b, err := EncodeSignatureDER(r, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r1, s1, err := DecodeSignatureDER(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r1.Cmp(r) != 0 || s1.Cmp(s) != 0 {
+			t.Errorf("got: (%d,%d), want: (%d,%d)", r1, s1, r, s)
+		}
+		if _, _, err := DecodeSignatureDER(append(b, 0)); err == nil {
+			t.Errorf("trailing data accepted")
+		}
+	})
+}
+
+func FuzzDecodeSignatureDER(f *testing.F) {
+	for _, sig := range [][2]*big.Int{
+		{big.NewInt(1), big.NewInt(1)},
+		{big.NewInt(128), big.NewInt(255)},
+		{BigFromHex("ffffffffffffffffffffffffffffffff"), big.NewInt(7)},
+	} {
+		b, _ := EncodeSignatureDER(sig[0], sig[1])
+		f.Add(b)
+	}
+	f.Add([]byte{0x30, 0x00})
+	f.Add([]byte{0x30, 0x80, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01, 0x00, 0x00})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r, s, err := DecodeSignatureDER(data)
+		if err != nil {
+			return
+		}
+		b, err := EncodeSignatureDER(r, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, data) {
+			t.Errorf("%x decoded to (%d,%d), which encodes to %x", data, r, s, b)
+		}
+	})
+}
+
+func TestSignHash(t *testing.T) {
+	// RFC 6979, Appendix A.2.5, message "sample"
+	curve := p256()
+	priv := BigFromHex("c9afa9d845ba75166b5c215767b1d6934e50c3db36e89b127b8a622b120f6721")
+	pubX, pubY := curve.ScalarBaseMult(priv)
+	cases := []struct {
+		hashID crypto.Hash
+		r, s   string
+	}{
+		{
+			crypto.SHA256,
+			"efd48b2aacb6a8fd1140dd9cd45e81d69d2c877b56aaf991c34d0ea84eaf3716",
+			"f7cb1c942d657c41d436c7a1b6e29f65f3e900dbb9aff4064dc4ab2f843acda8",
+		},
+		{
+			crypto.SHA512,
+			"8496a60b5e9b47c825488827e0495b0e3fa109ec4568fd3f8d1097678eb97f00",
+			"2362ab1adbe2b8adf9cb9edab740ea6049c028114f2460f96554f61fae3302fe",
+		},
+	}
+	for _, c := range cases {
+		h := c.hashID.New()
+		h.Write([]byte("sample"))
+		digest := h.Sum(nil)
+
+		r, s, err := curve.SignHash(priv, digest, c.hashID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Cmp(BigFromHex(c.r)) != 0 || s.Cmp(BigFromHex(c.s)) != 0 {
+			t.Errorf("%v: got: (%x, %x), want: (%s, %s)", c.hashID, r, s, c.r, c.s)
+		}
+		if !curve.Verify(pubX, pubY, digest, r, s) {
+			t.Errorf("%v: Verify failed", c.hashID)
+		}
+
+		r2, s2, _ := curve.SignHash(priv, digest, c.hashID)
+		if r2.Cmp(r) != 0 || s2.Cmp(s) != 0 {
+			t.Errorf("%v: signature is not deterministic", c.hashID)
+		}
+
+		if _, _, err := curve.SignHash(priv, digest[1:], c.hashID); err != ErrDigestLength {
+			t.Errorf("%v: got: %v, want: %v", c.hashID, err, ErrDigestLength)
+		}
+	}
+
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		priv, x, y, err := curve.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digest := sha512.Sum512([]byte("testing"))
+		r, s, err := curve.SignHash(priv, digest[:], crypto.SHA512)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !curve.Verify(x, y, digest[:], r, s) {
+			t.Error("Verify failed")
+		}
+	})
+}
+
+func TestSignASN1(t *testing.T) {
+	curve := p256()
+	hash := sha256.Sum256([]byte("testing"))
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stdlib signature, verified here
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !curve.VerifyASN1(key.X, key.Y, hash[:], sig) {
+		t.Errorf("crypto/ecdsa signature doesn't verify")
+	}
+
+	// our signature, verified by the stdlib
+	sig, err = curve.SignASN1(rand.Reader, key.D, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.VerifyASN1(&key.PublicKey, hash[:], sig) {
+		t.Errorf("signature doesn't verify with crypto/ecdsa")
+	}
+	if !curve.VerifyASN1(key.X, key.Y, hash[:], sig) {
+		t.Errorf("signature doesn't verify")
+	}
+
+	other := sha256.Sum256([]byte("other"))
+	if curve.VerifyASN1(key.X, key.Y, other[:], sig) {
+		t.Errorf("signature verifies for another hash")
+	}
+	if curve.VerifyASN1(key.X, key.Y, hash[:], append(sig, 0)) {
+		t.Errorf("signature with trailing data verifies")
+	}
+
+	if _, err := curve.SignASN1(bytes.NewReader(nil), key.D, hash[:]); err == nil {
+		t.Errorf("got: nil error from an empty reader")
+	}
+}
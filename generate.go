@@ -0,0 +1,105 @@
+package ecc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var ErrCurveNotFound = errors.New("ecc: no suitable curve found")
+
+// maxCurveCandidates bounds the number of (A, B) candidates tried by
+// GenerateCurveFromSeed.
+const maxCurveCandidates = 1000
+
+// expandSeed returns a bits-bit integer derived from seed, label and counter
+// by concatenating SHA-256(seed || label || counter || block).
+func expandSeed(seed []byte, label string, counter uint32, bits int) *big.Int {
+	var buf []byte
+	for block := uint32(0); len(buf)*8 < bits; block++ {
+		h := sha256.New()
+		h.Write(seed)
+		h.Write([]byte(label))
+		binary.Write(h, binary.BigEndian, counter)
+		binary.Write(h, binary.BigEndian, block)
+		buf = h.Sum(buf)
+	}
+	n := new(big.Int).SetBytes(buf)
+	return n.Rsh(n, uint(len(buf)*8-bits))
+}
+
+// GenerateCurveFromSeed deterministically derives a curve of prime order over
+// a bits-bit prime field from seed. P, A, B and the base Point are all
+// derived by hashing the seed, and the order is found with Schoof, so anyone
+// can reproduce the curve from the seed, which is recorded in Seed.
+// Candidates which are singular, not of prime order, or anomalous (N = P) are
+// rejected.
+//
+// Since Schoof is slow, this is only practical for small fields.
+func GenerateCurveFromSeed(bits int, seed []byte) (*Curve, error) {
+	if bits < 3 {
+		return nil, errors.New("ecc: field size too small")
+	}
+
+	p := expandSeed(seed, "P", 0, bits)
+	p.SetBit(p, bits-1, 1)
+	p = NextPrime(p.Sub(p, big.NewInt(1)))
+	if p.BitLen() != bits {
+		// wrap around to the least bits-bit prime
+		p = NextPrime(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)))
+	}
+
+	for i := uint32(0); i < maxCurveCandidates; i++ {
+		a := expandSeed(seed, "A", i, bits+64)
+		b := expandSeed(seed, "B", i, bits+64)
+		c := &Curve{
+			P:       p,
+			A:       a.Mod(a, p),
+			B:       b.Mod(b, p),
+			H:       big.NewInt(1),
+			BitSize: bits,
+		}
+		if _, err := c.JInvariant(); err != nil {
+			continue
+		}
+
+		n, err := c.Schoof()
+		if err != nil || !n.ProbablyPrime(20) || n.Cmp(p) == 0 {
+			continue
+		}
+		c.N = n
+
+		for j := uint32(0); c.Gx == nil; j++ {
+			x := expandSeed(seed, "G", j, bits+64)
+			x.Mod(x, p)
+			y, ny, ok := c.LiftX(x)
+			if !ok || y.Sign() == 0 {
+				continue
+			}
+			if y.Bit(0) == 1 {
+				y = ny
+			}
+			c.Gx, c.Gy = x, y
+		}
+		// guard against a miscounted order
+		if x, y := c.ScalarBaseMult(n); !IsInfinity(x, y) {
+			continue
+		}
+
+		c.Seed = append([]byte(nil), seed...)
+		return c, nil
+	}
+
+	return nil, ErrCurveNotFound
+}
+
+// GenerateCurve is GenerateCurveFromSeed with a 32-byte seed read from rand.
+func GenerateCurve(rand io.Reader, bits int) (*Curve, error) {
+	seed := make([]byte, 32)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, err
+	}
+	return GenerateCurveFromSeed(bits, seed)
+}
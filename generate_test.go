@@ -0,0 +1,56 @@
+package ecc
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestGenerateCurveFromSeed(t *testing.T) {
+	seed := []byte("nothing up my sleeve")
+	c1, err := GenerateCurveFromSeed(12, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := GenerateCurveFromSeed(12, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c1.P.BitLen() != 12 {
+		t.Errorf("got a %d-bit field, want 12", c1.P.BitLen())
+	}
+	if !c1.N.ProbablyPrime(20) {
+		t.Errorf("N = %d is not prime", c1.N)
+	}
+	if !c1.IsOnCurve(c1.Gx, c1.Gy) {
+		t.Errorf("base Point is not on the curve")
+	}
+	if !bytes.Equal(c1.Seed, seed) {
+		t.Errorf("got seed %q, want %q", c1.Seed, seed)
+	}
+
+	if c1.P.Cmp(c2.P) != 0 || c1.A.Cmp(c2.A) != 0 || c1.B.Cmp(c2.B) != 0 ||
+		c1.Gx.Cmp(c2.Gx) != 0 || c1.Gy.Cmp(c2.Gy) != 0 || c1.N.Cmp(c2.N) != 0 {
+		t.Errorf("same seed gave different curves")
+	}
+}
+
+func TestGenerateCurve(t *testing.T) {
+	c1, err := GenerateCurve(rand.New(rand.NewSource(1)), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := GenerateCurve(rand.New(rand.NewSource(1)), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c1.Seed) != 32 || !bytes.Equal(c1.Seed, c2.Seed) || c1.P.Cmp(c2.P) != 0 ||
+		c1.A.Cmp(c2.A) != 0 || c1.B.Cmp(c2.B) != 0 || c1.N.Cmp(c2.N) != 0 {
+		t.Errorf("same Rand gave different curves")
+	}
+
+	if _, err := GenerateCurve(bytes.NewReader(nil), 12); err == nil {
+		t.Errorf("want an error from an empty reader")
+	}
+}
@@ -0,0 +1,65 @@
+package ecc
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"math/big"
+	"strings"
+)
+
+// ErrGoIdentifier is returned by GoSource when the variable name is not a Go
+// identifier.
+var ErrGoIdentifier = errors.New("ecc: not a Go identifier")
+
+// GoSource returns a Go statement declaring varName as a literal copy of the
+// curve, in the form of
+//
+//	var varName = &Curve{
+//		P: BigFromDecimal("..."),
+//		...
+//	}
+//
+// so that a generated curve can be pasted into code. Nil fields are left
+// out. The snippet refers to Curve and BigFromDecimal unqualified; outside
+// package ecc they need the ecc. prefix. It returns ErrGoIdentifier if
+// varName is not a Go identifier, such as "1x", "my var" or "func".
+func (c *Curve) GoSource(varName string) (string, error) {
+	if !token.IsIdentifier(varName) {
+		return "", ErrGoIdentifier
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "var %s = &Curve{\n", varName)
+	for _, f := range []struct {
+		name string
+		v    *big.Int
+	}{
+		{"P", c.P}, {"A", c.A}, {"B", c.B},
+		{"Gx", c.Gx}, {"Gy", c.Gy},
+		{"N", c.N}, {"H", c.H},
+	} {
+		if f.v != nil {
+			fmt.Fprintf(&sb, "%s: BigFromDecimal(%q),\n", f.name, f.v.String())
+		}
+	}
+	if c.BitSize != 0 {
+		fmt.Fprintf(&sb, "BitSize: %d,\n", c.BitSize)
+	}
+	if c.Name != "" {
+		fmt.Fprintf(&sb, "Name: %q,\n", c.Name)
+	}
+	if c.Seed != nil {
+		fmt.Fprintf(&sb, "Seed: %#v,\n", c.Seed)
+	}
+	sb.WriteString("}\n")
+
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		// varName is an identifier and the rest is generated, so this
+		// can't happen
+		panic("ecc: internal error: invalid Go source: " + err.Error())
+	}
+	return string(src), nil
+}
@@ -0,0 +1,97 @@
+package ecc
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+// parseGoSource reads back the curve declared by the output of GoSource.
+func parseGoSource(t *testing.T, src string) (string, *Curve) {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package ecc\n"+src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v\n%s", err, src)
+	}
+	spec := f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+	lit := spec.Values[0].(*ast.UnaryExpr).X.(*ast.CompositeLit)
+
+	c := new(Curve)
+	ints := map[string]**big.Int{
+		"P": &c.P, "A": &c.A, "B": &c.B,
+		"Gx": &c.Gx, "Gy": &c.Gy, "N": &c.N, "H": &c.H,
+	}
+	for _, e := range lit.Elts {
+		kv := e.(*ast.KeyValueExpr)
+		key := kv.Key.(*ast.Ident).Name
+		switch key {
+		case "BitSize":
+			c.BitSize, _ = strconv.Atoi(kv.Value.(*ast.BasicLit).Value)
+		case "Name":
+			c.Name, _ = strconv.Unquote(kv.Value.(*ast.BasicLit).Value)
+		case "Seed":
+			for _, b := range kv.Value.(*ast.CompositeLit).Elts {
+				v, _ := strconv.ParseUint(b.(*ast.BasicLit).Value, 0, 8)
+				c.Seed = append(c.Seed, byte(v))
+			}
+		default:
+			call := kv.Value.(*ast.CallExpr)
+			if name := call.Fun.(*ast.Ident).Name; name != "BigFromDecimal" {
+				t.Fatalf("%s: got: %s, want: BigFromDecimal", key, name)
+			}
+			s, _ := strconv.Unquote(call.Args[0].(*ast.BasicLit).Value)
+			*ints[key] = BigFromDecimal(s)
+		}
+	}
+	return spec.Names[0].Name, c
+}
+
+func TestGoSource(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		curve.Name = "test curve"
+		curve.Seed = []byte{0x00, 0x7f, 0xff}
+
+		src, err := curve.GoSource("myCurve")
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, c := parseGoSource(t, src)
+		if name != "myCurve" {
+			t.Errorf("name got: %s, want: myCurve", name)
+		}
+		for _, f := range [][2]*big.Int{
+			{c.P, curve.P}, {c.A, curve.A}, {c.B, curve.B},
+			{c.Gx, curve.Gx}, {c.Gy, curve.Gy}, {c.N, curve.N}, {c.H, curve.H},
+		} {
+			if f[0] == nil || f[0].Cmp(f[1]) != 0 {
+				t.Errorf("got: %v, want: %v", f[0], f[1])
+			}
+		}
+		if c.BitSize != curve.BitSize || c.Name != curve.Name || !bytes.Equal(c.Seed, curve.Seed) {
+			t.Errorf("got: %d %q %x, want: %d %q %x", c.BitSize, c.Name, c.Seed, curve.BitSize, curve.Name, curve.Seed)
+		}
+	})
+
+	// nil fields are left out
+	src, err := (&Curve{P: big.NewInt(7)}).GoSource("c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, c := parseGoSource(t, src)
+	if c.P.Int64() != 7 || c.A != nil || c.N != nil || c.BitSize != 0 || c.Seed != nil {
+		t.Errorf("got: %+v, want: only P", c)
+	}
+
+	for _, name := range []string{"", "1x", "my var", "func", "a.b", "x-y"} {
+		if _, err := (&Curve{P: big.NewInt(7)}).GoSource(name); err != ErrGoIdentifier {
+			t.Errorf("GoSource(%q): got: %v, want: %v", name, err, ErrGoIdentifier)
+		}
+	}
+	if _, err := (&Curve{P: big.NewInt(7)}).GoSource("é_1"); err != nil {
+		t.Errorf("GoSource(%q): got: %v, want: nil", "é_1", err)
+	}
+}
@@ -0,0 +1,50 @@
+package ecc
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrHenselSingular is returned when a Newton step can't be taken
+	// because the derivative vanishes modulo P.
+	ErrHenselSingular = errors.New("ecc: derivative vanishes modulo p")
+	ErrLiftExponent   = errors.New("ecc: lift exponent must be positive")
+)
+
+// HenselLiftX lifts the x-coordinate of a Point on the curve over F_p to
+// Z/p^k. The y-coordinate is held at y1 of LiftX(xModP), and x is refined by
+// Newton iteration on f(X) - y1², where f is the right-hand side of the curve
+// equation, so that (x, y1) satisfies the equation modulo p^k and x = xModP
+// modulo p. It is an error if xModP is not the x-coordinate of a Point, k is
+// less than one, or f'(xModP) = 0 modulo p.
+func (c *Curve) HenselLiftX(xModP, k *big.Int) (*big.Int, error) {
+	if k.Sign() <= 0 {
+		return nil, ErrLiftExponent
+	}
+	x := new(big.Int).Mod(xModP, c.P)
+	y, _, ok := c.LiftX(x)
+	if !ok {
+		return nil, ErrNotOnCurve
+	}
+
+	g := NewPolyFromBigInt(new(big.Int).Sub(c.B, new(big.Int).Mul(y, y)), c.A, new(big.Int), big.NewInt(1))
+	if _, d := g.EvalWithDeriv(x, c.P); d.Sign() == 0 {
+		return nil, ErrHenselSingular
+	}
+
+	// each step doubles the number of correct p-adic digits
+	for prec := big.NewInt(1); prec.Cmp(k) < 0; {
+		prec.Lsh(prec, 1)
+		if prec.Cmp(k) > 0 {
+			prec.Set(k)
+		}
+		m := new(big.Int).Exp(c.P, prec, nil)
+		v, d := g.EvalWithDeriv(x, m)
+		d.ModInverse(d, m)
+		x.Sub(x, v.Mul(v, d))
+		x.Mod(x, m)
+	}
+
+	return x, nil
+}
@@ -0,0 +1,43 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestHenselLiftX(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		for _, k := range []int64{1, 2, 3, 5, 8} {
+			K := big.NewInt(k)
+			x, err := curve.HenselLiftX(curve.Gx, K)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if new(big.Int).Mod(x, curve.P).Cmp(curve.Gx) != 0 {
+				t.Errorf("k = %d: got: %d, which is not %d modulo p", k, x, curve.Gx)
+			}
+
+			y, _, _ := curve.LiftX(curve.Gx)
+			pk := new(big.Int).Exp(curve.P, K, nil)
+			lhs := new(big.Int).Mul(y, y)
+			lhs.Mod(lhs, pk)
+			if rhs := curve.poly().Eval(x, pk); lhs.Cmp(rhs) != 0 {
+				t.Errorf("k = %d: y² = %d, x³ + ax + b = %d modulo p^k", k, lhs, rhs)
+			}
+		}
+
+		if _, err := curve.HenselLiftX(curve.Gx, big.NewInt(0)); err != ErrLiftExponent {
+			t.Errorf("k = 0: got: %v, want: %v", err, ErrLiftExponent)
+		}
+	})
+
+	// y² = x³ + x + 1 over F_19: f(5) = 17 is a square and f'(5) = 3·25 + 1 = 0
+	c := &Curve{P: big.NewInt(19), A: big.NewInt(1), B: big.NewInt(1)}
+	if _, err := c.HenselLiftX(big.NewInt(5), big.NewInt(3)); err != ErrHenselSingular {
+		t.Errorf("got: %v, want: %v", err, ErrHenselSingular)
+	}
+	// f(4) = 69 = 12 is not a square modulo 19
+	if _, err := c.HenselLiftX(big.NewInt(4), big.NewInt(3)); err != ErrNotOnCurve {
+		t.Errorf("got: %v, want: %v", err, ErrNotOnCurve)
+	}
+}
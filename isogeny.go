@@ -0,0 +1,157 @@
+package ecc
+
+import (
+	"errors"
+	"math/big"
+)
+
+var ErrInvalidKernel = errors.New("ecc: invalid kernel polynomial")
+
+// Isogeny computes the odd degree isogeny whose kernel is given by the kernel
+// poly, that is, the poly whose roots are the x-coordinates of the non-zero
+// points of the kernel (a factor of a DivPoly). It returns the codomain curve
+// and the rational map between the two curves, using Vélu's formulas in
+// Kohel's form. Kernels containing 2-torsion points are not supported. It
+// returns ErrInvalidKernel unless kernel divides ψ_ℓ, ℓ = 2·deg+1, and its
+// roots are closed under multiplication by 2, ..., deg, which makes them a
+// subgroup when ℓ is prime.
+func (c *Curve) Isogeny(kernel Poly) (*Curve, func(x, y *big.Int) (*big.Int, *big.Int), error) {
+	q, f := c.P, c.poly()
+
+	psi := NewPolyFromBigInt(kernel...).sanitize(q)
+	n := psi.Deg()
+	if n < 1 {
+		return nil, nil, ErrInvalidKernel
+	}
+	psi = psi.Monic(q)
+	if !psi.GCD(f, q).IsOne() {
+		return nil, nil, ErrInvalidKernel
+	}
+	ell := int64(2*n + 1)
+	if _, r := c.DivPoly(ell).Div(psi, q); !r.isZero() {
+		return nil, nil, ErrInvalidKernel
+	}
+	if !c.kernelClosed(psi) {
+		return nil, nil, ErrInvalidKernel
+	}
+
+	// psi = x^n - s1·x^(n-1) + s2·x^(n-2) - s3·x^(n-3) + ...
+	s := make([]*big.Int, 4)
+	for i := range s {
+		s[i] = new(big.Int)
+		if i <= n {
+			s[i].Set(psi[n-i])
+			if i&0x1 == 1 {
+				s[i].Neg(s[i])
+			}
+		}
+	}
+	s1, s2, s3 := s[1], s[2], s[3]
+	nn := big.NewInt(int64(n))
+
+	// t = 6(s1² - 2s2) + 2An
+	t := new(big.Int).Mul(s1, s1)
+	t.Sub(t, new(big.Int).Lsh(s2, 1))
+	t.Mul(t, big.NewInt(6))
+	t.Add(t, new(big.Int).Mul(new(big.Int).Lsh(c.A, 1), nn))
+
+	// w = 10(s1³ - 3s1s2 + 3s3) + 6As1 + 4Bn
+	w := new(big.Int).Exp(s1, big.NewInt(3), nil)
+	w.Sub(w, new(big.Int).Mul(big.NewInt(3), new(big.Int).Mul(s1, s2)))
+	w.Add(w, new(big.Int).Mul(big.NewInt(3), s3))
+	w.Mul(w, big.NewInt(10))
+	w.Add(w, new(big.Int).Mul(big.NewInt(6), new(big.Int).Mul(c.A, s1)))
+	w.Add(w, new(big.Int).Mul(big.NewInt(4), new(big.Int).Mul(c.B, nn)))
+
+	a := new(big.Int).Mul(t, big.NewInt(5))
+	a.Sub(c.A, a).Mod(a, q)
+	b := new(big.Int).Mul(w, big.NewInt(7))
+	b.Sub(c.B, b).Mod(b, q)
+
+	e := &Curve{
+		P:       new(big.Int).Set(q),
+		A:       a,
+		B:       b,
+		BitSize: c.BitSize,
+	}
+	if c.N != nil {
+		e.N = new(big.Int).Set(c.N)
+	}
+	if c.H != nil {
+		e.H = new(big.Int).Set(c.H)
+	}
+
+	// x ↦ num/den where
+	// num = (ℓx - 2s1)psi² - 4f(psi''psi - psi'²) - 2f'psi'psi
+	// den = psi²
+	d1 := psi.Deriv(q)
+	d2 := d1.Deriv(q)
+	den := psi.Mul(psi, q)
+	lin := NewPolyFromBigInt(new(big.Int).Neg(new(big.Int).Lsh(s1, 1)), big.NewInt(ell))
+	num := lin.Mul(den, q).
+		Sub(f.Mul(d2.Mul(psi, q).Sub(d1.Mul(d1, q), q), q).MulInt(4, q), q).
+		Sub(f.Deriv(q).Mul(d1.Mul(psi, q), q).MulInt(2, q), q)
+
+	// y ↦ y·(num/den)'
+	dnum := num.Deriv(q).Mul(den, q).Sub(num.Mul(den.Deriv(q), q), q)
+
+	phi := func(x, y *big.Int) (*big.Int, *big.Int) {
+		if IsInfinity(x, y) {
+			return new(big.Int), new(big.Int)
+		}
+		d := den.Eval(x, q)
+		if d.Sign() == 0 {
+			return new(big.Int), new(big.Int)
+		}
+		dinv := d.ModInverse(d, q)
+
+		xOut := num.Eval(x, q)
+		xOut.Mul(xOut, dinv).Mod(xOut, q)
+
+		yOut := dnum.Eval(x, q)
+		yOut.Mul(yOut, dinv).Mul(yOut, dinv).Mul(yOut, y).Mod(yOut, q)
+
+		return xOut, yOut
+	}
+
+	return e, phi, nil
+}
+
+// kernelClosed reports whether, for every root x(P) of psi and 2 <= k <=
+// deg psi, kP is the Point at infinity or x(kP) is a root of psi too.
+// Writing x(kP) = N/D with the division polynomials, this holds iff psi
+// divides ψ_k·D^deg·psi(N/D), which is computed modulo psi; psi must be
+// coprime to x³ + Ax + B.
+func (c *Curve) kernelClosed(psi Poly) bool {
+	q, f := c.P, c.poly()
+	qr := NewQring(psi, q)
+	n := psi.Deg()
+	x := NewPolyFromInt(0, 1)
+	f4 := qr.Reduce(f.MulInt(4, q))
+
+	for k := int64(2); k <= int64(n); k++ {
+		// DivPoly(k) is ψ_k for odd k and 2y·ψ_k for even k
+		dk, dm, dp := c.DivPoly(k), c.DivPoly(k-1), c.DivPoly(k+1)
+		dk2 := qr.Mul(dk, dk)
+		var num, den Poly
+		if k&0x1 == 1 {
+			den = qr.Mul(f4, dk2)
+			num = qr.Mul(x, den).Sub(qr.Mul(dm, dp), q)
+		} else {
+			den = dk2
+			num = qr.Mul(x, den).Sub(qr.Mul(f4, qr.Mul(dm, dp)), q)
+		}
+
+		// Horner's rule on the homogenized psi(num/den)
+		h := NewPolyFromBigInt(psi[n])
+		denPow := NewPolyFromInt(1)
+		for i := n - 1; i >= 0; i-- {
+			denPow = qr.Mul(denPow, den)
+			h = qr.Mul(h, num).Add(qr.Mul(denPow, NewPolyFromBigInt(psi[i])), q)
+		}
+		if !qr.Mul(h, dk).isZero() {
+			return false
+		}
+	}
+	return true
+}
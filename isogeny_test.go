@@ -0,0 +1,120 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIsogeny(t *testing.T) {
+	// #E = 80, (49, 45) has order 5
+	c := &Curve{
+		P:       big.NewInt(97),
+		A:       big.NewInt(46),
+		B:       big.NewInt(74),
+		BitSize: 7,
+	}
+	gx, gy := big.NewInt(49), big.NewInt(45)
+	g2x, _ := c.Double(gx, gy)
+
+	kernel := NewPolyFromInt(1).
+		Mul(NewPolyFromBigInt(new(big.Int).Neg(gx), big.NewInt(1)), c.P).
+		Mul(NewPolyFromBigInt(new(big.Int).Neg(g2x), big.NewInt(1)), c.P)
+
+	e, phi, err := c.Isogeny(kernel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(c *Curve) int {
+		n := 1
+		for x := big.NewInt(0); x.Cmp(c.P) < 0; x.Add(x, big.NewInt(1)) {
+			n += 1 + big.Jacobi(c.evaluatePolynomial(x), c.P)
+		}
+		return n
+	}
+
+	kernelSize := 1
+	for x := big.NewInt(0); x.Cmp(c.P) < 0; x.Add(x, big.NewInt(1)) {
+		y := new(big.Int).ModSqrt(c.evaluatePolynomial(x), c.P)
+		if y == nil {
+			continue
+		}
+		ny := new(big.Int).Sub(c.P, y)
+		ny.Mod(ny, c.P)
+		ys := []*big.Int{y}
+		if ny.Cmp(y) != 0 {
+			ys = append(ys, ny)
+		}
+		for _, y := range ys {
+			ix, iy := phi(x, y)
+			if ix.Sign() == 0 && iy.Sign() == 0 {
+				kernelSize++
+				continue
+			}
+			if !e.IsOnCurve(ix, iy) {
+				t.Errorf("phi(%d,%d) = (%d,%d) is not on %s", x, y, ix, iy, e.poly())
+			}
+		}
+	}
+	if want := 2*kernel.Deg() + 1; kernelSize != want {
+		t.Errorf("got kernel of size %d, want: %d", kernelSize, want)
+	}
+	if count(c) != count(e) {
+		t.Errorf("#E = %d, #E' = %d", count(c), count(e))
+	}
+
+	// phi is a group homomorphism
+	px, py := big.NewInt(57), big.NewInt(0)
+	qx, qy := c.Add(px, py, gx, gy)
+	ax, ay := phi(px, py)
+	bx, by := phi(qx, qy)
+	sx, sy := e.Add(ax, ay, bx, by)
+	tx, ty := phi(c.Add(px, py, qx, qy))
+	if sx.Cmp(tx) != 0 || sy.Cmp(ty) != 0 {
+		t.Errorf("phi(P)+phi(Q) = (%d,%d), phi(P+Q) = (%d,%d)", sx, sy, tx, ty)
+	}
+}
+
+func TestIsogenyInvalidKernel(t *testing.T) {
+	c := &Curve{
+		P: big.NewInt(97),
+		A: big.NewInt(46),
+		B: big.NewInt(74),
+	}
+	for _, kernel := range []Poly{
+		NewPolyFromInt(1),
+		NewPolyFromInt(-57, 1), // 2-torsion
+		NewPolyFromInt(1, 1),
+	} {
+		if _, _, err := c.Isogeny(kernel); err != ErrInvalidKernel {
+			t.Errorf("Isogeny(%v): got: %v, want: %v", kernel, err, ErrInvalidKernel)
+		}
+	}
+}
+
+func TestIsogenyKernelSubgroup(t *testing.T) {
+	// all of E[5] is defined over F_101 on y² = x³ + 2x + 26: ψ_5 splits into
+	// 12 linear factors, paired up by the 6 subgroups of order 5
+	c := &Curve{
+		P: big.NewInt(101),
+		A: big.NewInt(2),
+		B: big.NewInt(26),
+	}
+	roots := []int{7, 17, 27, 50, 58, 64, 72, 73, 74, 76, 89, 100}
+	subgroups := map[[2]int]bool{
+		{7, 74}: true, {17, 50}: true, {27, 64}: true,
+		{58, 72}: true, {73, 76}: true, {89, 100}: true,
+	}
+	for i := range roots {
+		for j := i + 1; j < len(roots); j++ {
+			kernel := NewPolyFromInt(-roots[i], 1).Mul(NewPolyFromInt(-roots[j], 1), c.P)
+			want := error(nil)
+			if !subgroups[[2]int{roots[i], roots[j]}] {
+				want = ErrInvalidKernel
+			}
+			if _, _, err := c.Isogeny(kernel); err != want {
+				t.Errorf("Isogeny(%v): got: %v, want: %v", kernel, err, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package ecc
+
+import "math/big"
+
+// Two curves y² = x³ + A₁x + B₁ and y² = x³ + A₂x + B₂ over F_p are
+// isomorphic over F_p when A₂ = u⁴A₁ and B₂ = u⁶B₁ for some u ≠ 0 in F_p, by
+// (x, y) ↦ (u²x, u³y). Isomorphic curves share the j-invariant and the
+// group of points.
+
+// IsomorphicTo reports whether the curve is isomorphic to o over F_P.
+func (c *Curve) IsomorphicTo(o *Curve) bool {
+	P := c.P
+	if P.Cmp(o.P) != 0 {
+		return false
+	}
+	a1, b1 := c.ReduceField(c.A), c.ReduceField(c.B)
+	a2, b2 := c.ReduceField(o.A), c.ReduceField(o.B)
+	if (a1.Sign() == 0) != (a2.Sign() == 0) || (b1.Sign() == 0) != (b2.Sign() == 0) {
+		return false
+	}
+
+	switch {
+	case a1.Sign() == 0 && b1.Sign() == 0:
+		return true
+	case a1.Sign() == 0:
+		// j = 0: B₂/B₁ must be a sixth power
+		return isPowerResidue(ratio(b2, b1, P), 6, P)
+	case b1.Sign() == 0:
+		// j = 1728: A₂/A₁ must be a fourth power
+		return isPowerResidue(ratio(a2, a1, P), 4, P)
+	}
+
+	// u² = w = (B₂/B₁)/(A₂/A₁) must be a square with w² = A₂/A₁ and
+	// w³ = B₂/B₁
+	ra, rb := ratio(a2, a1, P), ratio(b2, b1, P)
+	w := ratio(rb, ra, P)
+	w2 := new(big.Int).Mul(w, w)
+	w3 := new(big.Int).Mul(w2, w)
+	return w2.Mod(w2, P).Cmp(ra) == 0 && w3.Mod(w3, P).Cmp(rb) == 0 && Legendre(w, P) == 1
+}
+
+// CanonicalForm returns a representative of the isomorphism class of the
+// curve over F_P, the same for all curves IsomorphicTo it, so that candidate
+// curves can be deduplicated before counting points. For j ≠ 0, 1728 it has
+// A = B = A³/B², or A = d²A³/B², B = d³A³/B² with d the least non-residue
+// when A/B is not a square; for j = 0 or 1728 the non-zero coefficient is
+// the least positive integer in its class. N and H carry over, since
+// isomorphic curves have the same group, but the base Point does not.
+func (c *Curve) CanonicalForm() *Curve {
+	P := c.P
+	a, b := c.ReduceField(c.A), c.ReduceField(c.B)
+	r := &Curve{
+		P:       new(big.Int).Set(P),
+		BitSize: c.BitSize,
+	}
+	if c.N != nil {
+		r.N = new(big.Int).Set(c.N)
+	}
+	if c.H != nil {
+		r.H = new(big.Int).Set(c.H)
+	}
+
+	switch {
+	case a.Sign() == 0 && b.Sign() == 0:
+		r.A, r.B = a, b
+	case a.Sign() == 0:
+		r.A, r.B = a, leastInClass(b, 6, P)
+	case b.Sign() == 0:
+		r.A, r.B = leastInClass(a, 4, P), b
+	default:
+		// k = A³/B² is fixed by j
+		k := new(big.Int).Exp(a, big.NewInt(3), P)
+		k = ratio(k, new(big.Int).Mul(b, b), P)
+		if Legendre(ratio(a, b, P), P) == 1 {
+			r.A, r.B = k, new(big.Int).Set(k)
+			break
+		}
+		d := nonResidue(P)
+		d2 := new(big.Int).Mul(d, d)
+		d3 := new(big.Int).Mul(d2, d)
+		r.A = d2.Mul(d2, k).Mod(d2, P)
+		r.B = d3.Mul(d3, k).Mod(d3, P)
+	}
+	return r
+}
+
+// ratio returns x/y modulo the prime p.
+func ratio(x, y, p *big.Int) *big.Int {
+	r := new(big.Int).ModInverse(y, p)
+	r.Mul(r, x)
+	return r.Mod(r, p)
+}
+
+// isPowerResidue reports whether x ≠ 0 is an e-th power modulo the prime p.
+func isPowerResidue(x *big.Int, e int64, p *big.Int) bool {
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	g := new(big.Int).GCD(nil, nil, big.NewInt(e), pm1)
+	return new(big.Int).Exp(x, pm1.Div(pm1, g), p).Cmp(big.NewInt(1)) == 0
+}
+
+// leastInClass returns the least positive t with t/x an e-th power modulo
+// the prime p.
+func leastInClass(x *big.Int, e int64, p *big.Int) *big.Int {
+	for t := big.NewInt(1); ; t.Add(t, big.NewInt(1)) {
+		if isPowerResidue(ratio(t, x, p), e, p) {
+			return t
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// isomorphic reports whether (a1, b1) and (a2, b2) are related by some u, by
+// trying every u.
+func isomorphic(a1, b1, a2, b2, p int64) bool {
+	for u := int64(1); u < p; u++ {
+		u2 := u * u % p
+		u4 := u2 * u2 % p
+		u6 := u4 * u2 % p
+		if u4*a1%p == a2 && u6*b1%p == b2 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestIsomorphicTo(t *testing.T) {
+	toy := sampleCurves()["TOY"]
+	// u = 3
+	iso := &Curve{P: big.NewInt(29), A: big.NewInt(81 * 4 % 29), B: big.NewInt(729 * 20 % 29)}
+	if !toy.IsomorphicTo(iso) || !iso.IsomorphicTo(toy) {
+		t.Errorf("y² = x³ + %dx + %d is isomorphic to TOY", iso.A, iso.B)
+	}
+	if toy.IsomorphicTo(toy.Twist()) {
+		t.Errorf("TOY is isomorphic to its twist")
+	}
+	if c := toy.CanonicalForm(); c.A.Cmp(iso.CanonicalForm().A) != 0 || c.B.Cmp(iso.CanonicalForm().B) != 0 || c.N.Cmp(toy.N) != 0 {
+		t.Errorf("canonical forms differ: %d, %d", c.A, c.B)
+	}
+
+	// every pair of curves over F_p, against trying every u
+	for _, p := range []int64{7, 11, 13, 17} {
+		P := big.NewInt(p)
+		curves := make([]*Curve, 0, p*p)
+		for a := int64(0); a < p; a++ {
+			for b := int64(0); b < p; b++ {
+				curves = append(curves, &Curve{P: P, A: big.NewInt(a), B: big.NewInt(b)})
+			}
+		}
+		canon := make([]*Curve, len(curves))
+		for i, c := range curves {
+			canon[i] = c.CanonicalForm()
+			if !c.IsomorphicTo(canon[i]) {
+				t.Errorf("p = %d: (%d, %d) is not isomorphic to its canonical form (%d, %d)", p, c.A, c.B, canon[i].A, canon[i].B)
+			}
+		}
+		for i, c1 := range curves {
+			for j, c2 := range curves {
+				want := isomorphic(c1.A.Int64(), c1.B.Int64(), c2.A.Int64(), c2.B.Int64(), p)
+				if got := c1.IsomorphicTo(c2); got != want {
+					t.Errorf("p = %d: (%d, %d) ≅ (%d, %d) got: %v, want: %v", p, c1.A, c1.B, c2.A, c2.B, got, want)
+				}
+				same := canon[i].A.Cmp(canon[j].A) == 0 && canon[i].B.Cmp(canon[j].B) == 0
+				if same != want {
+					t.Errorf("p = %d: canonical forms of (%d, %d) and (%d, %d) equal: %v, want: %v", p, c1.A, c1.B, c2.A, c2.B, same, want)
+				}
+			}
+		}
+	}
+
+	if toy.IsomorphicTo(sampleCurves()["SMALL"]) {
+		t.Errorf("curves over different fields are isomorphic")
+	}
+}
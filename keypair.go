@@ -0,0 +1,122 @@
+package ecc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// KeyPair holds a private key together with its public key on Curve.
+type KeyPair struct {
+	Private          *big.Int
+	PublicX, PublicY *big.Int
+	Curve            *Curve
+}
+
+// GenerateKeyPair returns a new key pair.
+func (c *Curve) GenerateKeyPair(rand io.Reader) (*KeyPair, error) {
+	priv, x, y, err := c.GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{
+		Private: priv,
+		PublicX: x,
+		PublicY: y,
+		Curve:   c,
+	}, nil
+}
+
+// GenerateKeyCompressed is like GenerateKey, but returns the public key in
+// compressed form.
+func (c *Curve) GenerateKeyCompressed(rand io.Reader) (priv *big.Int, pubCompressed []byte, err error) {
+	priv, x, y, err := c.GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv, c.MarshalCompressed(x, y), nil
+}
+
+// Sign signs hash with the private key.
+func (kp *KeyPair) Sign(hash []byte) (r, s *big.Int) {
+	return kp.Curve.Sign(kp.Private, hash)
+}
+
+// PublicBytes returns the public key in uncompressed form.
+func (kp *KeyPair) PublicBytes() []byte {
+	return kp.Curve.Marshal(kp.PublicX, kp.PublicY)
+}
+
+// PublicCompressed returns the public key in compressed form.
+func (kp *KeyPair) PublicCompressed() []byte {
+	return kp.Curve.MarshalCompressed(kp.PublicX, kp.PublicY)
+}
+
+// ECDH returns the shared secret of the key pair and the peer's public key.
+func (kp *KeyPair) ECDH(peer *KeyPair) ([]byte, error) {
+	return kp.Curve.ECDH(kp.Private, peer.PublicX, peer.PublicY)
+}
+
+// SignerOpts are the options of Signer.Sign. Hash is the hash the digest was
+// made with, and if Deterministic is set the nonce is derived from the key
+// and digest as in RFC 6979, as by SignHash, instead of being read from the
+// rand passed to Sign. Any other crypto.SignerOpts, such as a bare
+// crypto.Hash, asks for a randomized signature.
+type SignerOpts struct {
+	Hash          crypto.Hash
+	Deterministic bool
+}
+
+// HashFunc returns Hash, so that SignerOpts is a crypto.SignerOpts.
+func (o *SignerOpts) HashFunc() crypto.Hash {
+	return o.Hash
+}
+
+// Signer is a crypto.Signer backed by a KeyPair. Its signatures are in the
+// DER encoding of EncodeSignatureDER, like those of crypto/ecdsa.
+type Signer struct {
+	kp *KeyPair
+}
+
+// Signer returns a crypto.Signer for the key pair.
+func (kp *KeyPair) Signer() *Signer {
+	return &Signer{kp}
+}
+
+// Public returns the public key as a *KeyPair with no private key.
+func (sg *Signer) Public() crypto.PublicKey {
+	return &KeyPair{PublicX: sg.kp.PublicX, PublicY: sg.kp.PublicY, Curve: sg.kp.Curve}
+}
+
+// Sign signs digest, the result of hashing a message with opts.HashFunc(),
+// or of an unknown hash if opts is nil or its hash is 0. It is an error if
+// the hash is set but digest is not of its size, or a deterministic
+// signature is asked for without a hash to run HMAC with. If rnd is nil,
+// crypto/rand.Reader is used.
+func (sg *Signer) Sign(rnd io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	c, priv := sg.kp.Curve, sg.kp.Private
+	var h crypto.Hash
+	if opts != nil {
+		h = opts.HashFunc()
+	}
+	if h != 0 && len(digest) != h.Size() {
+		return nil, ErrDigestLength
+	}
+
+	if o, ok := opts.(*SignerOpts); ok && o.Deterministic {
+		if h == 0 {
+			return nil, ErrHashUnavailable
+		}
+		r, s, err := c.SignHash(priv, digest, h)
+		if err != nil {
+			return nil, err
+		}
+		return EncodeSignatureDER(r, s)
+	}
+
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	return c.SignASN1(rnd, priv, digest)
+}
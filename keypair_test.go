@@ -0,0 +1,124 @@
+package ecc
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestKeyPair(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		kp1, err := curve.GenerateKeyPair(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kp2, err := curve.GenerateKeyPair(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hashed := []byte("testing")
+		r, s := kp1.Sign(hashed)
+		if !curve.Verify(kp1.PublicX, kp1.PublicY, hashed, r, s) {
+			t.Errorf("Verify failed")
+		}
+
+		x, y := curve.Unmarshal(kp1.PublicBytes())
+		if x == nil || x.Cmp(kp1.PublicX) != 0 || y.Cmp(kp1.PublicY) != 0 {
+			t.Errorf("PublicBytes does not round-trip")
+		}
+
+		s1, err := kp1.ECDH(kp2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s2, err := kp2.ECDH(kp1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(s1, s2) {
+			t.Errorf("shared secrets differ: %x, %x", s1, s2)
+		}
+	})
+}
+
+func TestPublicCompressed(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		kp, err := curve.GenerateKeyPair(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y := curve.UnmarshalCompressed(kp.PublicCompressed())
+		if x == nil || x.Cmp(kp.PublicX) != 0 || y.Cmp(kp.PublicY) != 0 {
+			t.Errorf("PublicCompressed does not round-trip")
+		}
+
+		priv, pub, err := curve.GenerateKeyCompressed(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y = curve.UnmarshalCompressed(pub)
+		if x == nil {
+			t.Fatal("UnmarshalCompressed failed")
+		}
+		if err := curve.CheckKeyPair(priv, x, y); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestSigner(t *testing.T) {
+	curve := p256()
+	kp, err := curve.GenerateKeyPair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signer crypto.Signer = kp.Signer()
+	pub := signer.Public().(*KeyPair)
+	if pub.Private != nil || pub.PublicX.Cmp(kp.PublicX) != 0 || pub.PublicY.Cmp(kp.PublicY) != 0 {
+		t.Errorf("Public does not match the key pair")
+	}
+	stdPub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: kp.PublicX, Y: kp.PublicY}
+
+	digest := sha256.Sum256([]byte("testing"))
+	sign := func(opts crypto.SignerOpts) []byte {
+		t.Helper()
+		sig, err := signer.Sign(rand.Reader, digest[:], opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !curve.VerifyASN1(kp.PublicX, kp.PublicY, digest[:], sig) || !ecdsa.VerifyASN1(stdPub, digest[:], sig) {
+			t.Errorf("%+v: signature does not verify", opts)
+		}
+		return sig
+	}
+
+	det := &SignerOpts{Hash: crypto.SHA256, Deterministic: true}
+	sig1, sig2 := sign(det), sign(det)
+	if !bytes.Equal(sig1, sig2) {
+		t.Errorf("deterministic signatures differ")
+	}
+	r, s, _ := curve.SignHash(kp.Private, digest[:], crypto.SHA256)
+	if want, _ := EncodeSignatureDER(r, s); !bytes.Equal(sig1, want) {
+		t.Errorf("got: %x, want: %x", sig1, want)
+	}
+
+	for _, opts := range []crypto.SignerOpts{nil, crypto.SHA256, &SignerOpts{Hash: crypto.SHA256}} {
+		if sig1, sig2 := sign(opts), sign(opts); bytes.Equal(sig1, sig2) {
+			t.Errorf("%+v: randomized signatures are equal", opts)
+		}
+	}
+
+	for _, opts := range []crypto.SignerOpts{crypto.SHA256, det, &SignerOpts{Hash: crypto.SHA384}} {
+		if _, err := signer.Sign(rand.Reader, digest[1:], opts); err != ErrDigestLength {
+			t.Errorf("%+v: got: %v, want: %v", opts, err, ErrDigestLength)
+		}
+	}
+	if _, err := signer.Sign(rand.Reader, digest[:], &SignerOpts{Deterministic: true}); err != ErrHashUnavailable {
+		t.Errorf("got: %v, want: %v", err, ErrHashUnavailable)
+	}
+}
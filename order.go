@@ -0,0 +1,242 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// HasseInterval returns the bounds P+1-2√P <= #E <= P+1+2√P given by Hasse's
+// theorem, rounded inwards.
+func (c *Curve) HasseInterval() (lo, hi *big.Int) {
+	s := new(big.Int).Lsh(c.P, 2)
+	s.Sqrt(s) // ⌊2√P⌋
+
+	lo = new(big.Int).Add(c.P, big.NewInt(1))
+	hi = new(big.Int).Add(lo, s)
+	lo.Sub(lo, s)
+	return
+}
+
+// OrderInHasse reports whether candidate is a plausible order of the group of
+// points, that is, whether it lies in the HasseInterval.
+func (c *Curve) OrderInHasse(candidate *big.Int) bool {
+	lo, hi := c.HasseInterval()
+	return candidate.Cmp(lo) >= 0 && candidate.Cmp(hi) <= 0
+}
+
+// ErrFieldTooLarge is returned by OrderBruteForce when P is above
+// maxBruteForceP.
+var ErrFieldTooLarge = errors.New("ecc: field too large to count points")
+
+// maxBruteForceP bounds the fields OrderBruteForce enumerates.
+const maxBruteForceP = 1 << 20
+
+// OrderBruteForce returns #E by counting, for each x in F_P, the solutions
+// of y² = x³ + ax + b with Legendre, plus one for the Point at infinity. It
+// is slow but straightforward, and meant as a check of Schoof on small
+// curves. It is an error if P > 2^20. Over F_2, where Legendre isn't
+// defined, it counts the pairs (x, y) directly.
+func (c *Curve) OrderBruteForce() (*big.Int, error) {
+	if c.P.Cmp(big.NewInt(maxBruteForceP)) > 0 {
+		return nil, ErrFieldTooLarge
+	}
+
+	n := int64(1)
+	if c.P.Cmp(big.NewInt(2)) == 0 {
+		for x := int64(0); x < 2; x++ {
+			for y := int64(0); y < 2; y++ {
+				if c.IsOnCurve(big.NewInt(x), big.NewInt(y)) {
+					n++
+				}
+			}
+		}
+		return big.NewInt(n), nil
+	}
+
+	for x := new(big.Int); x.Cmp(c.P) < 0; x.Add(x, big.NewInt(1)) {
+		n += int64(1 + Legendre(c.evaluatePolynomial(x), c.P))
+	}
+	return big.NewInt(n), nil
+}
+
+// ErrFactorization is returned when an order can't be fully factored.
+var ErrFactorization = errors.New("ecc: failed to factor the order")
+
+// ErrGroupStructure is returned when the sampled exponent is inconsistent
+// with the group order.
+var ErrGroupStructure = errors.New("ecc: inconsistent group structure")
+
+// SetOrder records a known #E, for example one taken from a database, so
+// that Trace, IsAnomalous, IsSupersingular, TwistOrder, EmbeddingDegree and
+// the others which need it don't run Schoof. It takes precedence over N·H.
+// order is copied; it is the caller's duty that it is correct.
+func (c *Curve) SetOrder(order *big.Int) {
+	c.order = new(big.Int).Set(order)
+}
+
+// groupOrder returns #E, as given by SetOrder, from N and H if both are set,
+// or by Schoof.
+func (c *Curve) groupOrder() (*big.Int, error) {
+	if c.order != nil {
+		return new(big.Int).Set(c.order), nil
+	}
+	if c.N != nil && c.H != nil {
+		return new(big.Int).Mul(c.N, c.H), nil
+	}
+	return c.Schoof()
+}
+
+// primeFactors returns the distinct prime factors of n.
+func primeFactors(n *big.Int) ([]*big.Int, error) {
+	factors, err := Factorize(n)
+	if err != nil {
+		return nil, err
+	}
+	var distinct []*big.Int
+	for _, f := range factors {
+		dup := false
+		for _, d := range distinct {
+			if d.Cmp(f) == 0 {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			distinct = append(distinct, f)
+		}
+	}
+	return distinct, nil
+}
+
+// pointOrder returns the order of (x, y), given a multiple n of it and the
+// distinct prime factors of n.
+func (c *Curve) pointOrder(x, y, n *big.Int, factors []*big.Int) *big.Int {
+	order := new(big.Int).Set(n)
+	for _, f := range factors {
+		for {
+			q, r := new(big.Int).QuoRem(order, f, new(big.Int))
+			if r.Sign() != 0 {
+				break
+			}
+			if qx, qy := c.ScalarMult(x, y, q); !IsInfinity(qx, qy) {
+				break
+			}
+			order = q
+		}
+	}
+	return order
+}
+
+// randomPoint returns a random Point on the curve.
+func (c *Curve) randomPoint(rnd io.Reader) (x, y *big.Int, err error) {
+	for {
+		if x, err = rand.Int(rnd, c.P); err != nil {
+			return nil, nil, err
+		}
+		y1, y2, ok := c.LiftX(x)
+		if !ok {
+			continue
+		}
+		b := make([]byte, 1)
+		if _, err = io.ReadFull(rnd, b); err != nil {
+			return nil, nil, err
+		}
+		if b[0]&1 == 1 {
+			return x, y2, nil
+		}
+		return x, y1, nil
+	}
+}
+
+// groupStructureRounds is the number of random points whose orders must all
+// divide the exponent found so far before GroupStructure accepts it.
+const groupStructureRounds = 20
+
+// GroupStructure returns n1 and n2 such that the group of points is
+// isomorphic to Z/n1 × Z/n2 with n2 | n1. n1 is the exponent of the group,
+// found as the lcm of the orders of random points, and n2 = #E/n1, which must
+// divide both n1 and P-1 since the Weil pairing maps E[n2] onto the n2-th roots
+// of unity in F_p. The answer is correct with overwhelming probability.
+func (c *Curve) GroupStructure() (n1, n2 *big.Int, err error) {
+	n, err := c.groupOrder()
+	if err != nil {
+		return nil, nil, err
+	}
+	factors, err := primeFactors(n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pMinus1 := new(big.Int).Sub(c.P, big.NewInt(1))
+	n1 = big.NewInt(1)
+	for stable := 0; stable < groupStructureRounds; {
+		x, y, err := c.randomPoint(c.random())
+		if err != nil {
+			return nil, nil, err
+		}
+		o := c.pointOrder(x, y, n, factors)
+
+		g := new(big.Int).GCD(nil, nil, n1, o)
+		if g.Cmp(o) == 0 {
+			stable++
+			continue
+		}
+		n1.Mul(n1, o.Div(o, g)) // lcm
+		stable = 0
+	}
+
+	n2, r := new(big.Int).QuoRem(n, n1, new(big.Int))
+	if r.Sign() != 0 ||
+		new(big.Int).Mod(n1, n2).Sign() != 0 ||
+		new(big.Int).Mod(pMinus1, n2).Sign() != 0 {
+		return nil, nil, ErrGroupStructure
+	}
+	return n1, n2, nil
+}
+
+var (
+	// ErrOrderUnknown is returned when N or H is needed but not set.
+	ErrOrderUnknown = errors.New("ecc: order or cofactor not set")
+	// ErrGeneratorNotFound is returned when no Point of order N is found,
+	// which means N·H is not the order of the group.
+	ErrGeneratorNotFound = errors.New("ecc: no point of order N found")
+)
+
+// maxGeneratorTries bounds the random points FindGenerator tries.
+const maxGeneratorTries = 100
+
+// FindGenerator finds a base Point of order N, sets it as (Gx, Gy) and
+// returns it. It lifts random x-coordinates read from rand to points and
+// multiplies them by the cofactor H to land in the subgroup of order N,
+// keeping the first whose order is exactly N.
+func (c *Curve) FindGenerator(rand io.Reader) (gx, gy *big.Int, err error) {
+	if c.N == nil || c.H == nil {
+		return nil, nil, ErrOrderUnknown
+	}
+	factors, err := primeFactors(c.N)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := 0; i < maxGeneratorTries; i++ {
+		x, y, err := c.randomPoint(rand)
+		if err != nil {
+			return nil, nil, err
+		}
+		gx, gy = c.ScalarMult(x, y, c.H)
+		if IsInfinity(gx, gy) {
+			continue
+		}
+		if nx, ny := c.ScalarMult(gx, gy, c.N); !IsInfinity(nx, ny) {
+			return nil, nil, ErrGeneratorNotFound
+		}
+		if c.pointOrder(gx, gy, c.N, factors).Cmp(c.N) != 0 {
+			continue
+		}
+		c.Gx, c.Gy = gx, gy
+		return gx, gy, nil
+	}
+	return nil, nil, ErrGeneratorNotFound
+}
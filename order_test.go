@@ -0,0 +1,138 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestHasseInterval(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		n := new(big.Int).Mul(curve.N, curve.H)
+		if !curve.OrderInHasse(n) {
+			lo, hi := curve.HasseInterval()
+			t.Errorf("N·H = %d is not in [%d, %d]", n, lo, hi)
+		}
+		if curve.OrderInHasse(new(big.Int).Lsh(curve.P, 1)) {
+			t.Errorf("2P is in the Hasse interval")
+		}
+	})
+
+	// 2√29 = 10.77...
+	lo, hi := sampleCurves()["TOY"].HasseInterval()
+	if lo.Int64() != 20 || hi.Int64() != 40 {
+		t.Errorf("got: [%d, %d], want: [20, 40]", lo, hi)
+	}
+}
+
+func TestGroupStructure(t *testing.T) {
+	cases := []struct {
+		c      *Curve
+		n1, n2 int64
+	}{
+		{&Curve{P: big.NewInt(29), A: big.NewInt(1), B: big.NewInt(2)}, 12, 2},
+		{&Curve{P: big.NewInt(97), A: big.NewInt(2), B: big.NewInt(10)}, 24, 4},
+		{sampleCurves()["COFACTOR"], 10084, 1},
+	}
+	for _, c := range cases {
+		n1, n2, err := c.c.GroupStructure()
+		if err != nil {
+			t.Errorf("%s: got error: %v", c.c.poly(), err)
+			continue
+		}
+		if n1.Int64() != c.n1 || n2.Int64() != c.n2 {
+			t.Errorf("%s: got: Z/%d × Z/%d, want: Z/%d × Z/%d", c.c.poly(), n1, n2, c.n1, c.n2)
+		}
+	}
+}
+
+func TestFindGenerator(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		curve.Gx, curve.Gy = nil, nil
+		gx, gy, err := curve.FindGenerator(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if curve.Gx != gx || curve.Gy != gy {
+			t.Error("generator not set on the curve")
+		}
+		if x, y := curve.ScalarMult(gx, gy, curve.N); x.Sign() != 0 || y.Sign() != 0 {
+			t.Errorf("N·G: got: (%d, %d), want: (0, 0)", x, y)
+		}
+		factors, err := primeFactors(curve.N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range factors {
+			if x, y := curve.ScalarMult(gx, gy, new(big.Int).Div(curve.N, f)); x.Sign() == 0 && y.Sign() == 0 {
+				t.Errorf("(N/%d)·G = ∞", f)
+			}
+		}
+	})
+
+	// the whole group of COFACTOR is cyclic of order 10084 = 2²·2521
+	c := sampleCurves()["COFACTOR"]
+	c.N, c.H = big.NewInt(10084), big.NewInt(1)
+	gx, gy, err := c.FindGenerator(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []int64{10084 / 2, 10084 / 2521} {
+		if x, y := c.ScalarMult(gx, gy, big.NewInt(k)); x.Sign() == 0 && y.Sign() == 0 {
+			t.Errorf("%d·G = ∞", k)
+		}
+	}
+
+	// N·H is not the order of the group
+	c.N = big.NewInt(10083)
+	if _, _, err := c.FindGenerator(rand.Reader); err != ErrGeneratorNotFound {
+		t.Errorf("got: %v, want: %v", err, ErrGeneratorNotFound)
+	}
+	if _, _, err := (&Curve{P: c.P, A: c.A, B: c.B}).FindGenerator(rand.Reader); err != ErrOrderUnknown {
+		t.Errorf("got: %v, want: %v", err, ErrOrderUnknown)
+	}
+}
+
+func TestOrderBruteForce(t *testing.T) {
+	cases := []*Curve{
+		{P: big.NewInt(97), A: big.NewInt(46), B: big.NewInt(74)},
+		{P: big.NewInt(19), A: big.NewInt(2), B: big.NewInt(1)},
+		{P: big.NewInt(7919), A: big.NewInt(1001), B: big.NewInt(75)},
+	}
+	for _, c := range cases {
+		got, err := c.OrderBruteForce()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := c.Schoof()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("y² = x³ + %dx + %d over F_%d got: %d, want: %d", c.A, c.B, c.P, got, want)
+		}
+	}
+
+	// over F_2 each x has exactly one y, and Legendre would panic
+	for a := int64(0); a < 2; a++ {
+		for b := int64(0); b < 2; b++ {
+			c := &Curve{P: big.NewInt(2), A: big.NewInt(a), B: big.NewInt(b)}
+			if got, err := c.OrderBruteForce(); err != nil || got.Int64() != 3 {
+				t.Errorf("y² = x³ + %dx + %d over F_2 got: %d, %v, want: 3", a, b, got, err)
+			}
+		}
+	}
+
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		got, err := curve.OrderBruteForce()
+		if curve.P.Cmp(big.NewInt(maxBruteForceP)) > 0 {
+			if err != ErrFieldTooLarge {
+				t.Errorf("got: %v, want: %v", err, ErrFieldTooLarge)
+			}
+			return
+		}
+		if want := new(big.Int).Mul(curve.N, curve.H); err != nil || got.Cmp(want) != 0 {
+			t.Errorf("got: %d, %v, want: %d", got, err, want)
+		}
+	})
+}
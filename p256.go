@@ -0,0 +1,110 @@
+package ecc
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// p256P is the prime of the field of P-256, 2²⁵⁶ - 2²²⁴ + 2¹⁹² + 2⁹⁶ - 1.
+var p256P, _ = new(big.Int).SetString("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff", 16)
+
+// p256Limbs is p256P in 32-bit limbs, least significant first.
+var p256Limbs = [8]uint32{0xffffffff, 0xffffffff, 0xffffffff, 0, 0, 0, 1, 0xffffffff}
+
+// reduce sets z to z mod P and returns it. Over the field of P-256 it uses
+// p256Reduce for the products of the Jacobian formulas, which are below
+// 2⁵¹²; everything else goes to big.Int.Mod.
+func (c *Curve) reduce(z *big.Int) *big.Int {
+	if z.Sign() >= 0 && z.BitLen() <= 512 && c.P.BitLen() == 256 && c.P.Cmp(p256P) == 0 {
+		return p256Reduce(z)
+	}
+	return z.Mod(z, c.P)
+}
+
+// p256Reduce sets z, with 0 <= z < 2⁵¹², to z mod p256P and returns it.
+// Since 2²⁵⁶ ≡ 2²²⁴ - 2¹⁹² - 2⁹⁶ + 1, the upper half of z folds into the
+// lower half as a sum of nine 256-bit terms made of its 32-bit words, as in
+// FIPS 186-4, Appendix D.2.3.
+func p256Reduce(z *big.Int) *big.Int {
+	var w [16]int64
+	words := z.Bits()
+	for i, d := range words {
+		if bits.UintSize == 64 {
+			w[2*i] = int64(uint32(d))
+			w[2*i+1] = int64(uint64(d) >> 32)
+		} else {
+			w[i] = int64(d)
+		}
+	}
+
+	// s1 + 2s2 + 2s3 + s4 + s5 - s6 - s7 - s8 - s9
+	acc := [8]int64{
+		w[0] + w[8] + w[9] - w[11] - w[12] - w[13] - w[14],
+		w[1] + w[9] + w[10] - w[12] - w[13] - w[14] - w[15],
+		w[2] + w[10] + w[11] - w[13] - w[14] - w[15],
+		w[3] + 2*w[11] + 2*w[12] + w[13] - w[15] - w[8] - w[9],
+		w[4] + 2*w[12] + 2*w[13] + w[14] - w[9] - w[10],
+		w[5] + 2*w[13] + 2*w[14] + w[15] - w[10] - w[11],
+		w[6] + 3*w[14] + 2*w[15] + w[13] - w[8] - w[9],
+		w[7] + 3*w[15] + w[8] - w[10] - w[11] - w[12] - w[13],
+	}
+
+	var limbs [8]uint32
+	carry := p256Carry(&acc, &limbs)
+	// fold the carry back in until the value fits in 256 bits; a couple of
+	// rounds at most, as the carry shrinks to ±1 after the first
+	for carry != 0 {
+		for i, l := range limbs {
+			acc[i] = int64(l)
+		}
+		acc[0] += carry
+		acc[3] -= carry
+		acc[6] -= carry
+		acc[7] += carry
+		carry = p256Carry(&acc, &limbs)
+	}
+
+	// now 0 <= limbs < 2²⁵⁶ < 2p
+	if !p256Less(&limbs, &p256Limbs) {
+		var borrow uint32
+		for i := range limbs {
+			limbs[i], borrow = bits.Sub32(limbs[i], p256Limbs[i], borrow)
+		}
+	}
+
+	n := 256 / bits.UintSize
+	if cap(words) < n {
+		words = make([]big.Word, n)
+	}
+	words = words[:n]
+	for i := range words {
+		if bits.UintSize == 64 {
+			words[i] = big.Word(uint64(limbs[2*i]) | uint64(limbs[2*i+1])<<32)
+		} else {
+			words[i] = big.Word(limbs[i])
+		}
+	}
+	return z.SetBits(words)
+}
+
+// p256Carry propagates the carries of acc into 32-bit limbs and returns the
+// signed carry out of the top limb.
+func p256Carry(acc *[8]int64, limbs *[8]uint32) int64 {
+	var carry int64
+	for i, a := range acc {
+		a += carry
+		limbs[i] = uint32(a)
+		carry = a >> 32
+	}
+	return carry
+}
+
+// p256Less reports whether a < b.
+func p256Less(a, b *[8]uint32) bool {
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
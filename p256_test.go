@@ -0,0 +1,98 @@
+package ecc
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestP256Reduce(t *testing.T) {
+	max := new(big.Int).Lsh(big.NewInt(1), 512)
+	inputs := []*big.Int{
+		new(big.Int),
+		big.NewInt(1),
+		new(big.Int).Sub(p256P, big.NewInt(1)),
+		new(big.Int).Set(p256P),
+		new(big.Int).Add(p256P, big.NewInt(1)),
+		new(big.Int).Lsh(big.NewInt(1), 256),
+		new(big.Int).Mul(p256P, p256P),
+		new(big.Int).Mul(new(big.Int).Sub(p256P, big.NewInt(1)), new(big.Int).Sub(p256P, big.NewInt(1))),
+		new(big.Int).Sub(max, big.NewInt(1)),
+	}
+	for i := 0; i < 1000; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, n)
+	}
+	for _, n := range inputs {
+		want := new(big.Int).Mod(n, p256P)
+		if got := p256Reduce(new(big.Int).Set(n)); got.Cmp(want) != 0 {
+			t.Errorf("%x: got: %x, want: %x", n, got, want)
+		}
+	}
+
+	// out of range or over another field, reduce is plain Mod
+	curve := sampleCurves()["S256"]
+	for _, n := range []*big.Int{big.NewInt(-5), new(big.Int).Lsh(big.NewInt(3), 600), inputs[len(inputs)-1]} {
+		want := new(big.Int).Mod(n, curve.P)
+		if got := curve.reduce(new(big.Int).Set(n)); got.Cmp(want) != 0 {
+			t.Errorf("%x: got: %x, want: %x", n, got, want)
+		}
+	}
+}
+
+func TestP256ScalarMult(t *testing.T) {
+	curve := p256()
+	std := elliptic.P256()
+	for i := 0; i < 20; i++ {
+		k, _, _, _ := curve.GenerateKey(rand.Reader)
+		x, y := curve.ScalarBaseMult(k)
+		wx, wy := std.ScalarBaseMult(k.Bytes())
+		if x.Cmp(wx) != 0 || y.Cmp(wy) != 0 {
+			t.Fatalf("got: (%x,%x), want: (%x,%x)", x, y, wx, wy)
+		}
+
+		x1, y1 := curve.Add(x, y, curve.Gx, curve.Gy)
+		wx, wy = std.Add(wx, wy, std.Params().Gx, std.Params().Gy)
+		if x1.Cmp(wx) != 0 || y1.Cmp(wy) != 0 {
+			t.Fatalf("got: (%x,%x), want: (%x,%x)", x1, y1, wx, wy)
+		}
+		x1, y1 = curve.Double(x, y)
+		wx, wy = std.Double(x, y)
+		if x1.Cmp(wx) != 0 || y1.Cmp(wy) != 0 {
+			t.Fatalf("got: (%x,%x), want: (%x,%x)", x1, y1, wx, wy)
+		}
+	}
+}
+
+func BenchmarkP256Reduce(b *testing.B) {
+	max := new(big.Int).Lsh(big.NewInt(1), 512)
+	n, _ := rand.Int(rand.Reader, max)
+	z := new(big.Int)
+	b.Run("p256Reduce", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p256Reduce(z.Set(n))
+		}
+	})
+	b.Run("Mod", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			z.Mod(z.Set(n), p256P)
+		}
+	})
+}
+
+func BenchmarkScalarMultP256(b *testing.B) {
+	curve := p256()
+	_, x, y, _ := curve.GenerateKey(rand.Reader)
+	priv, _, _, _ := curve.GenerateKey(rand.Reader)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x, y = curve.ScalarMult(x, y, priv)
+	}
+}
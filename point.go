@@ -0,0 +1,124 @@
+package ecc
+
+import "math/big"
+
+// Point is a Point (X, Y) on Curve, for chaining group operations without
+// passing bare coordinate pairs around. As with the Curve methods, (0, 0)
+// is the Point at infinity; a nil X or Y is read as 0, so Point{Curve: c}
+// is the identity of c too. Two Points are on the same curve if their
+// curves have equal P, A, B and base point, even if they are distinct
+// *Curve values. The methods panic if a Point is not on its curve, or if
+// two Points are on different curves.
+type Point struct {
+	X, Y  *big.Int
+	Curve *Curve
+}
+
+// NewPoint returns the Point (x, y) on c. It returns ErrNotOnCurve if (x, y)
+// is neither on the curve nor the Point at infinity.
+func (c *Curve) NewPoint(x, y *big.Int) (*Point, error) {
+	if !IsInfinity(x, y) && !c.IsOnCurve(x, y) {
+		return nil, ErrNotOnCurve
+	}
+	return &Point{X: new(big.Int).Set(x), Y: new(big.Int).Set(y), Curve: c}, nil
+}
+
+// Infinity returns the Point at infinity on c.
+func (c *Curve) Infinity() *Point {
+	return &Point{X: new(big.Int), Y: new(big.Int), Curve: c}
+}
+
+// Generator returns the base Point (Gx, Gy) of c. It panics if c has none.
+func (c *Curve) Generator() *Point {
+	if c.Gx == nil || c.Gy == nil {
+		panic(ErrNoBasePoint.Error())
+	}
+	return &Point{X: new(big.Int).Set(c.Gx), Y: new(big.Int).Set(c.Gy), Curve: c}
+}
+
+// coords returns the coordinates of p, with nil read as 0.
+func (p *Point) coords() (*big.Int, *big.Int) {
+	x, y := p.X, p.Y
+	if x == nil {
+		x = new(big.Int)
+	}
+	if y == nil {
+		y = new(big.Int)
+	}
+	return x, y
+}
+
+// equalParams reports whether c and d have the same P, A, B and base point,
+// with A and B compared modulo P. A nil parameter, or a nil curve, equals
+// only another nil.
+func (c *Curve) equalParams(d *Curve) bool {
+	if c == nil || d == nil || c == d {
+		return c == d
+	}
+	eq := func(a, b *big.Int) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return a.Cmp(b) == 0
+	}
+	congruent := func(a, b *big.Int) bool {
+		if a == nil || b == nil || c.P == nil || c.P.Sign() == 0 {
+			return eq(a, b)
+		}
+		diff := new(big.Int).Sub(a, b)
+		return diff.Mod(diff, c.P).Sign() == 0
+	}
+	return eq(c.P, d.P) && congruent(c.A, d.A) && congruent(c.B, d.B) &&
+		eq(c.Gx, d.Gx) && eq(c.Gy, d.Gy)
+}
+
+func (p *Point) sameCurve(q *Point) {
+	if !p.Curve.equalParams(q.Curve) {
+		panic("ecc: points on different curves")
+	}
+}
+
+func (p *Point) with(x, y *big.Int) *Point {
+	return &Point{X: x, Y: y, Curve: p.Curve}
+}
+
+// Add returns p + q.
+func (p *Point) Add(q *Point) *Point {
+	p.sameCurve(q)
+	x1, y1 := p.coords()
+	x2, y2 := q.coords()
+	return p.with(p.Curve.Add(x1, y1, x2, y2))
+}
+
+// Double returns 2p.
+func (p *Point) Double() *Point {
+	x, y := p.coords()
+	return p.with(p.Curve.Double(x, y))
+}
+
+// ScalarMult returns k·p. As with Curve.ScalarMult, a negative k gives -(|k|·p).
+func (p *Point) ScalarMult(k *big.Int) *Point {
+	x, y := p.coords()
+	return p.with(p.Curve.ScalarMult(x, y, k))
+}
+
+// Neg returns -p.
+func (p *Point) Neg() *Point {
+	x, y := p.coords()
+	return p.with(p.Curve.Neg(x, y))
+}
+
+// Equal reports whether p and q are the same Point on the same curve.
+func (p *Point) Equal(q *Point) bool {
+	if !p.Curve.equalParams(q.Curve) {
+		return false
+	}
+	x1, y1 := p.coords()
+	x2, y2 := q.coords()
+	return x1.Cmp(x2) == 0 && y1.Cmp(y2) == 0
+}
+
+// IsInfinity reports whether p is the Point at infinity.
+func (p *Point) IsInfinity() bool {
+	return IsInfinity(p.coords())
+}
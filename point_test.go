@@ -0,0 +1,106 @@
+package ecc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestPoint(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		k, x, y, _ := curve.GenerateKey(rand.Reader)
+		p, err := curve.NewPoint(x, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := curve.Generator()
+		if !g.ScalarMult(k).Equal(p) {
+			t.Errorf("k·G: got: %v, want: (%d,%d)", g.ScalarMult(k), x, y)
+		}
+
+		check := func(name string, got *Point, wx, wy *big.Int) {
+			t.Helper()
+			if got.Curve != curve || got.X.Cmp(wx) != 0 || got.Y.Cmp(wy) != 0 {
+				t.Errorf("%s: got: (%d,%d), want: (%d,%d)", name, got.X, got.Y, wx, wy)
+			}
+		}
+		wx, wy := curve.Add(x, y, curve.Gx, curve.Gy)
+		check("Add", p.Add(g), wx, wy)
+		wx, wy = curve.Double(x, y)
+		check("Double", p.Double(), wx, wy)
+		wx, wy = curve.Neg(x, y)
+		check("Neg", p.Neg(), wx, wy)
+
+		// chaining through ∞ needs no special casing
+		inf := curve.Infinity()
+		if !p.Add(p.Neg()).IsInfinity() {
+			t.Errorf("p + (-p): got: %v, want: ∞", p.Add(p.Neg()))
+		}
+		if !g.ScalarMult(big.NewInt(-1)).Equal(g.Neg()) {
+			t.Errorf("-1·G: got: %v, want: %v", g.ScalarMult(big.NewInt(-1)), g.Neg())
+		}
+		if !g.ScalarMult(curve.N).IsInfinity() {
+			t.Errorf("N·G: got: %v, want: ∞", g.ScalarMult(curve.N))
+		}
+		if !inf.Add(p).Equal(p) || !p.Add(inf).Equal(p) {
+			t.Errorf("∞ + p: got: %v, want: %v", inf.Add(p), p)
+		}
+		if !inf.Double().IsInfinity() || !inf.Neg().IsInfinity() {
+			t.Errorf("2∞ or -∞ is not ∞")
+		}
+		zero := &Point{Curve: curve}
+		if !zero.IsInfinity() || !zero.Equal(inf) || !zero.Add(p).Equal(p) {
+			t.Errorf("Point{Curve: c} is not ∞")
+		}
+
+		if p.Equal(g.ScalarMult(new(big.Int).Add(k, big.NewInt(1)))) {
+			t.Errorf("k·G equals (k+1)·G")
+		}
+		if inf.Equal((&Curve{}).Infinity()) {
+			t.Errorf("points on different curves are equal")
+		}
+
+		// a copy of the curve is the same curve
+		cp := *curve
+		if q := (&Point{X: p.X, Y: p.Y, Curve: &cp}); !p.Equal(q) || !p.Add(q).Equal(p.Double()) {
+			t.Errorf("points on a copy of the curve are not equal")
+		}
+		shifted := cp
+		shifted.A = new(big.Int).Sub(curve.A, curve.P)
+		if !inf.Equal(shifted.Infinity()) {
+			t.Errorf("points on curves with A and A - P are not equal")
+		}
+		if (&Point{}).Equal(inf) || inf.Equal(&Point{}) {
+			t.Errorf("a Point with no curve equals one on a curve")
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != "ecc: points on different curves" {
+					t.Errorf("got panic: %v, want: ecc: points on different curves", r)
+				}
+			}()
+			(&Point{}).Add(inf)
+		}()
+		other := cp
+		other.B = new(big.Int).Add(curve.B, big.NewInt(1))
+		if inf.Equal(other.Infinity()) {
+			t.Errorf("points on curves with different B are equal")
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("adding points on different curves did not panic")
+				}
+			}()
+			inf.Add(other.Infinity())
+		}()
+
+		offY := new(big.Int).Add(y, big.NewInt(1))
+		if curve.IsOnCurve(x, offY) {
+			offY.Add(offY, big.NewInt(1))
+		}
+		if _, err := curve.NewPoint(x, offY); err != ErrNotOnCurve {
+			t.Errorf("got: %v, want: %v", err, ErrNotOnCurve)
+		}
+	})
+}
@@ -1,19 +1,35 @@
 package ecc
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 )
 
+var ErrInexactDivision = errors.New("ecc: polynomial division has a remainder")
+
 // https://github.com/jukworks/polynomial
 
 // Poly Data structure for a poly
 // Just an array in reverse
 // f(x) = 3x^3 + 2x + 1 => [1 2 0 3]
+// a nil or empty Poly is the zero poly, like [0]
 type Poly []*big.Int
 
+// orZero returns P, or [0] if P is empty
+func (p Poly) orZero() Poly {
+	if len(p) == 0 {
+		return NewPolyFromInt(0)
+	}
+	return p
+}
+
 // NewPolyFromBigInt generates a poly with given integers.
 func NewPolyFromBigInt(a ...*big.Int) Poly {
+	if len(a) == 0 {
+		return NewPolyFromInt(0)
+	}
 	alen := len(a)
 	p := make(Poly, alen)
 
@@ -25,7 +41,12 @@ func NewPolyFromBigInt(a ...*big.Int) Poly {
 }
 
 // NewPolyFromInt generates a poly with given integers.
+// it is meant for small literals; use NewPolyFromInt64 or NewPolyFromBigInt
+// for coefficients that may not fit in a 32-bit int
 func NewPolyFromInt(a ...int) Poly {
+	if len(a) == 0 {
+		a = []int{0}
+	}
 	alen := len(a)
 	p := make(Poly, alen)
 
@@ -36,11 +57,52 @@ func NewPolyFromInt(a ...int) Poly {
 	return p
 }
 
+// NewPolyFromInt64 generates a poly with given 64-bit integers.
+func NewPolyFromInt64(a ...int64) Poly {
+	if len(a) == 0 {
+		return NewPolyFromInt(0)
+	}
+	p := make(Poly, len(a))
+	for i := range a {
+		p[i] = big.NewInt(a[i])
+	}
+
+	return p
+}
+
+// NewSparsePoly generates a poly from a map of degree to coefficient.
+// missing degrees have zero coefficients,
+// for example, {101: 1, 0: 3} gives x^101 + 3
+func NewSparsePoly(terms map[int]*big.Int) Poly {
+	deg := 0
+	for d := range terms {
+		if d < 0 {
+			panic("ecc: negative degree")
+		}
+		if d > deg {
+			deg = d
+		}
+	}
+
+	p := make(Poly, deg+1)
+	for i := 0; i <= deg; i++ {
+		p[i] = new(big.Int)
+	}
+	for d, a := range terms {
+		p[d].Set(a)
+	}
+
+	return p.trim()
+}
+
 // trim makes sure that the highest coefficient never has zero value
 // when you add or subtract two polynomials, sometimes the highest coefficient
 // goes zero if you don't remove the highest and zero coefficient,
 // Deg() returns the wrong result
 func (p Poly) trim() Poly {
+	if len(p) == 0 {
+		return NewPolyFromInt(0)
+	}
 	deg := len(p) - 1
 	if p[deg].Sign() != 0 {
 		return p
@@ -58,6 +120,18 @@ func (p Poly) trim() Poly {
 	return p[:last+1]
 }
 
+// TrimCopy returns a trimmed deep-copy of P
+// unlike trim, the result never shares coefficients with P
+func (p Poly) TrimCopy() Poly {
+	t := p.trim()
+	q := make(Poly, len(t))
+	for i := 0; i < len(t); i++ {
+		q[i] = new(big.Int).Set(t[i])
+	}
+
+	return q
+}
+
 // sanitize does modular arithmetic with m
 func (p Poly) sanitize(m *big.Int) Poly {
 	for i := 0; i < len(p); i++ {
@@ -75,30 +149,50 @@ func (p Poly) Clone(adjust int) Poly {
 	if adjust < 0 {
 		return NewPolyFromInt(0)
 	}
+	p = p.orZero()
 
 	q := make(Poly, len(p)+adjust)
 	for i := 0; i < adjust; i++ {
 		q[i] = new(big.Int)
 	}
-	copy(q[adjust:], p)
+	for i := 0; i < len(p); i++ {
+		q[i+adjust] = new(big.Int).Set(p[i])
+	}
 
 	return q
 }
 
 // isZero checks if P = 0
 func (p Poly) isZero() bool {
-	return p.Deg() == 0 && p[0].Sign() == 0
+	return len(p) == 0 || p.Deg() == 0 && p[0].Sign() == 0
 }
 
 // Deg returns the degree
 // if p = x^3 + 2x^2 + 5, Deg() returns 3
 func (p Poly) Deg() int {
+	if len(p) == 0 {
+		return 0
+	}
 	return len(p) - 1
 }
 
 // String pretty print
 func (p Poly) String() string {
-	s := "["
+	return "[" + p.format(func(i int) string {
+		return "^" + fmt.Sprintf("%d", i)
+	}) + "]"
+}
+
+// LaTeX pretty print in LaTeX, e.g. 3x^{3} + 2x + 1
+func (p Poly) LaTeX() string {
+	return p.format(func(i int) string {
+		return "^{" + fmt.Sprintf("%d", i) + "}"
+	})
+}
+
+// format writes out the terms, with exponents greater than one formatted by sup
+func (p Poly) format(sup func(int) string) string {
+	s := ""
 	for i := len(p) - 1; i >= 0; i-- {
 		switch p[i].Sign() {
 		case -1:
@@ -123,23 +217,28 @@ func (p Poly) String() string {
 		if i > 0 {
 			s += "x"
 			if i > 1 {
-				s += "^" + fmt.Sprintf("%d", i)
+				s += sup(i)
 			}
 		}
 	}
-	if s == "[" {
-		s += "0"
+	if s == "" {
+		s = "0"
 	}
-	s += "]"
 
 	return s
 }
 
+// Coeffs returns a copy of the coefficients in ascending order of degree
+func (p Poly) Coeffs() []*big.Int {
+	return p.Clone(0)
+}
+
 // Cmp compares two polynomials and returns -1, 0, or 1
 // if P == Q, returns 0
 // if P > Q, returns 1
 // if P < Q, returns -1
 func (p Poly) Cmp(q Poly) int {
+	p, q = p.orZero(), q.orZero()
 	if len(p) > len(q) {
 		return 1
 	}
@@ -157,9 +256,47 @@ func (p Poly) Cmp(q Poly) int {
 	return 0
 }
 
+// Equal reports whether P and Q are the same polynomial
+// leading zero coefficients are ignored, so [1 0] equals [1]
+func (p Poly) Equal(q Poly) bool {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+
+	for i := 0; i < len(q); i++ {
+		if p[i].Cmp(q[i]) != 0 {
+			return false
+		}
+	}
+	for i := len(q); i < len(p); i++ {
+		if p[i].Sign() != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsConstant checks if P has no term of positive degree
+func (p Poly) IsConstant() bool {
+	for i := 1; i < len(p); i++ {
+		if p[i].Sign() != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsOne checks if P = 1
+func (p Poly) IsOne() bool {
+	return p.IsConstant() && len(p) > 0 && p[0].Cmp(big.NewInt(1)) == 0
+}
+
 // Add adds two polynomials
 // modulo m can be nil
 func (p Poly) Add(q Poly, m *big.Int) Poly {
+	p, q = p.orZero(), q.orZero()
 	if p.Cmp(q) < 0 {
 		return q.Add(p, m)
 	}
@@ -167,18 +304,15 @@ func (p Poly) Add(q Poly, m *big.Int) Poly {
 	r := p.Clone(0)
 
 	for i := 0; i < len(q); i++ {
-		r[i] = new(big.Int).Add(p[i], q[i])
-	}
-
-	for i := 0; i < len(q); i++ {
-		r[i].Mod(r[i], m)
+		r[i].Add(p[i], q[i])
 	}
 
-	return r.trim()
+	return r.sanitize(m)
 }
 
 // Neg returns a poly Q = -P
 func (p Poly) Neg() Poly {
+	p = p.orZero()
 	q := make(Poly, len(p))
 	for i := 0; i < len(p); i++ {
 		q[i] = new(big.Int).Neg(p[i])
@@ -190,6 +324,7 @@ func (p Poly) Neg() Poly {
 // Sub subtracts P from Q
 // Since we already have Add(), Sub() does Add(P, -Q)
 func (p Poly) Sub(q Poly, m *big.Int) Poly {
+	p, q = p.orZero(), q.orZero()
 	swap := false
 	s, t := p, q
 	if p.Cmp(q) < 0 {
@@ -204,7 +339,9 @@ func (p Poly) Sub(q Poly, m *big.Int) Poly {
 		for i := 0; i < ln; i++ {
 			r[i] = new(big.Int).Sub(s[i], t[i])
 		}
-		copy(r[ln:], s[ln:])
+		for i := ln; i < len(s); i++ {
+			r[i] = new(big.Int).Set(s[i])
+		}
 	} else {
 		for i := 0; i < ln; i++ {
 			r[i] = new(big.Int).Sub(t[i], s[i])
@@ -223,22 +360,79 @@ func (p Poly) Sub(q Poly, m *big.Int) Poly {
 
 // Mul returns P * Q
 func (p Poly) Mul(q Poly, m *big.Int) Poly {
-	r := make(Poly, len(p)+len(q)-1)
-	for i := 0; i < len(r); i++ {
-		r[i] = new(big.Int)
+	return p.MulInto(nil, q, m)
+}
+
+// MulInto returns P * Q like Mul, but stores it in dst
+// the backing array and coefficients of dst are reused, and grown only if needed
+// dst must not share coefficients with P, Q or anything else still in use
+func (p Poly) MulInto(dst Poly, q Poly, m *big.Int) Poly {
+	p, q = p.orZero(), q.orZero()
+	n := len(p) + len(q) - 1
+	if cap(dst) < n {
+		dst = append(dst[:cap(dst)], make(Poly, n-cap(dst))...)
+	}
+	dst = dst[:n]
+	for i := range dst {
+		if dst[i] == nil {
+			dst[i] = new(big.Int)
+		} else {
+			dst[i].SetInt64(0)
+		}
 	}
 
+	t := new(big.Int)
 	for i := 0; i < len(p); i++ {
 		for j := 0; j < len(q); j++ {
-			r[i+j].Add(r[i+j], new(big.Int).Mul(p[i], q[j]))
+			dst[i+j].Add(dst[i+j], t.Mul(p[i], q[j]))
 		}
 	}
 
-	return r.sanitize(m)
+	// like sanitize, but with the quotient and remainder buffers reused
+	r := new(big.Int)
+	for i := range dst {
+		t.QuoRem(dst[i], m, r)
+		if r.Sign() < 0 {
+			r.Add(r, m)
+		}
+		dst[i].Set(r)
+	}
+
+	return dst.trim()
 }
 
 func (p Poly) MulInt(a int, m *big.Int) Poly {
-	return p.Mul(NewPolyFromInt(a), m)
+	return p.ScaleInt(big.NewInt(int64(a)), m)
+}
+
+// ScaleInt returns a * P, multiplying each coefficient by a
+func (p Poly) ScaleInt(a *big.Int, m *big.Int) Poly {
+	p = p.orZero()
+	r := make(Poly, len(p))
+	for i := range p {
+		r[i] = new(big.Int).Mul(p[i], a)
+	}
+
+	return r.sanitize(m)
+}
+
+// ShiftLeft returns x^k * P, for k >= 0
+// all k+len(P) coefficients come from one allocation, and none is shared
+// with P
+func (p Poly) ShiftLeft(k int) Poly {
+	if k < 0 || p.isZero() {
+		return NewPolyFromInt(0)
+	}
+
+	q := make(Poly, k+len(p))
+	coeffs := make([]big.Int, k+len(p))
+	for i := range coeffs {
+		q[i] = &coeffs[i]
+	}
+	for i, c := range p {
+		q[k+i].Set(c)
+	}
+	return q
 }
 
 // Exp returns P^e mod M
@@ -258,12 +452,86 @@ func (p Poly) Exp(e *big.Int, m *big.Int) Poly {
 	return r.trim()
 }
 
+// expWindowBits returns the window width ExpWindow uses for an exponent of
+// the given bit length
+func expWindowBits(bits int) uint {
+	switch {
+	case bits <= 8:
+		return 1
+	case bits <= 24:
+		return 2
+	case bits <= 80:
+		return 3
+	case bits <= 240:
+		return 4
+	}
+	return 5
+}
+
+// ExpWindow returns P^e mod M like Exp, but with a left-to-right sliding
+// window over the bits of e, so that only one multiplication is made for
+// each run of up to k bits starting and ending with a one
+// the window width k grows with the size of e, and the odd powers
+// P, P^3, ..., P^(2^k-1) are computed beforehand
+// as with Exp, the sign of e is ignored
+func (p Poly) ExpWindow(e *big.Int, m *big.Int) Poly {
+	e = new(big.Int).Abs(e)
+	k := expWindowBits(e.BitLen())
+	odd := make([]Poly, 1<<(k-1))
+	odd[0] = p
+	if len(odd) > 1 {
+		p2 := p.Mul(p, m)
+		for i := 1; i < len(odd); i++ {
+			odd[i] = odd[i-1].Mul(p2, m)
+		}
+	}
+
+	r := NewPolyFromInt(1)
+	for i := e.BitLen() - 1; i >= 0; {
+		if e.Bit(i) == 0 {
+			r = r.Mul(r, m)
+			i--
+			continue
+		}
+
+		// the longest window e[i..l] of at most k bits with e[l] = 1
+		l := i - int(k) + 1
+		if l < 0 {
+			l = 0
+		}
+		for e.Bit(l) == 0 {
+			l++
+		}
+		v := 0
+		for j := i; j >= l; j-- {
+			v = v<<1 | int(e.Bit(j))
+			r = r.Mul(r, m)
+		}
+		r = r.Mul(odd[v>>1], m)
+		i = l - 1
+	}
+
+	return r.trim()
+}
+
 // Div returns (P / Q, P % Q)
+// if m is not positive, or the leading coefficient of Q is not invertible
+// modulo m (e.g. Q = 0), Div returns (nil, nil)
 func (p Poly) Div(q Poly, m *big.Int) (Poly, Poly) {
-	p.sanitize(m)
+	if m.Sign() <= 0 {
+		return nil, nil
+	}
+	p = p.TrimCopy().sanitize(m)
+	q = q.TrimCopy().sanitize(m)
+
+	qd := q.Deg()
+	inv := new(big.Int).ModInverse(q[qd], m)
+	if inv == nil {
+		return nil, nil
+	}
 
 	if len(p) < len(q) {
-		return NewPolyFromInt(0), p.Clone(0)
+		return NewPolyFromInt(0), p
 	}
 
 	quo := make(Poly, len(p)-len(q)+1)
@@ -272,7 +540,6 @@ func (p Poly) Div(q Poly, m *big.Int) (Poly, Poly) {
 	}
 	rem := p
 
-	qd := q.Deg()
 	for {
 		td := len(rem) - 1 // rem.Deg()
 		rd := td - qd
@@ -281,8 +548,7 @@ func (p Poly) Div(q Poly, m *big.Int) (Poly, Poly) {
 		}
 
 		r := quo[rd]
-		r.ModInverse(q[qd], m)
-		r.Mul(r, rem[td]).Mod(r, m)
+		r.Mul(inv, rem[td]).Mod(r, m)
 
 		u := make(Poly, len(q)+rd)
 		for i := 0; i < rd; i++ {
@@ -299,15 +565,66 @@ func (p Poly) Div(q Poly, m *big.Int) (Poly, Poly) {
 	return quo, rem
 }
 
+// DivExact returns P / Q
+// it is an error if Q does not divide P
+func (p Poly) DivExact(q Poly, m *big.Int) (Poly, error) {
+	quo, rem := p.Div(q, m)
+	if quo == nil {
+		return nil, ErrZeroDivision
+	}
+	if !rem.isZero() {
+		return nil, ErrInexactDivision
+	}
+
+	return quo, nil
+}
+
+// Monic returns P divided by its leading coefficient, or 0 if P = 0
 func (p Poly) Monic(m *big.Int) Poly {
+	if p.isZero() {
+		return NewPolyFromInt(0)
+	}
 	q := NewPolyFromBigInt(p[p.Deg()])
 	q, _ = p.Div(q, m)
 	return q
 }
 
+// Content returns the gcd of the coefficients of P over the integers
+// it takes the sign of the leading coefficient, so that PrimitivePart has a
+// positive leading coefficient, and is 0 if P = 0
+func (p Poly) Content() *big.Int {
+	t := p.trim()
+	c := new(big.Int)
+	for _, a := range t {
+		c.GCD(nil, nil, c, new(big.Int).Abs(a))
+	}
+	if t[t.Deg()].Sign() < 0 {
+		c.Neg(c)
+	}
+
+	return c
+}
+
+// PrimitivePart returns P / Content(P) over the integers
+// for example, -6x^2 + 4 gives 3x^2 - 2, and 0 gives 0
+func (p Poly) PrimitivePart() Poly {
+	c := p.Content()
+	if c.Sign() == 0 {
+		return NewPolyFromInt(0)
+	}
+
+	t := p.trim()
+	r := make(Poly, len(t))
+	for i := range t {
+		r[i] = new(big.Int).Quo(t[i], c)
+	}
+
+	return r
+}
+
 // Deriv derivative
 func (p Poly) Deriv(m *big.Int) Poly {
-	if len(p) == 1 {
+	if len(p) <= 1 {
 		return NewPolyFromInt(0)
 	}
 
@@ -363,9 +680,64 @@ func (p Poly) ModInverse(h Poly, m *big.Int) Poly {
 	return x.Mul(t, m)
 }
 
+// Roots returns the distinct roots of P in F_m, for a prime m, in ascending
+// order
+// it splits gcd(P, x^m - x), the product of the linear factors of P, by
+// Cantor-Zassenhaus; P = 0 has no roots listed
+func (p Poly) Roots(m *big.Int) []*big.Int {
+	f := p.TrimCopy().sanitize(m)
+	if f.IsConstant() {
+		return nil
+	}
+	if m.Cmp(big.NewInt(2)) == 0 {
+		var roots []*big.Int
+		for _, x := range []*big.Int{big.NewInt(0), big.NewInt(1)} {
+			if f.Eval(x, m).Sign() == 0 {
+				roots = append(roots, x)
+			}
+		}
+		return roots
+	}
+
+	x := NewPolyFromInt(0, 1)
+	xm := NewQring(f, m).Exp(x, m)
+	roots := splitLinear(f.GCD(xm.Sub(x, m), m), m)
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Cmp(roots[j]) < 0
+	})
+
+	return roots
+}
+
+// splitLinear returns the roots of a monic g that is a product of distinct
+// linear factors over F_m, for an odd prime m
+// for a shift a, gcd(g, (x + a)^((m-1)/2) - 1) picks the roots r with r + a
+// a non-zero square, which splits g for about half of all a
+func splitLinear(g Poly, m *big.Int) []*big.Int {
+	switch g.Deg() {
+	case 0:
+		return nil
+	case 1:
+		// g = x + g0
+		return []*big.Int{new(big.Int).Mod(new(big.Int).Neg(g[0]), m)}
+	}
+
+	qr := NewQring(g, m)
+	e := new(big.Int).Rsh(m, 1)
+	for a := int64(0); ; a++ {
+		h := qr.Exp(NewPolyFromInt64(a, 1), e).Sub(NewPolyFromInt(1), m)
+		h = g.GCD(h, m)
+		if d := h.Deg(); d > 0 && d < g.Deg() {
+			rest, _ := g.Div(h, m)
+			return append(splitLinear(h, m), splitLinear(rest, m)...)
+		}
+	}
+}
+
 // Eval returns p(v) where v is the given big integer
 func (p Poly) Eval(x *big.Int, m *big.Int) *big.Int {
-	ans := new(big.Int).Set(p[p.Deg()])
+	p = p.orZero()
+	ans := new(big.Int).Mod(p[p.Deg()], m)
 	for i := p.Deg() - 1; i >= 0; i-- {
 		ans.Mul(ans, x)
 		ans.Add(ans, p[i])
@@ -374,3 +746,40 @@ func (p Poly) Eval(x *big.Int, m *big.Int) *big.Int {
 
 	return ans
 }
+
+// EvalMulti returns p(x) for each x in xs, reusing scratch space across points
+func (p Poly) EvalMulti(xs []*big.Int, m *big.Int) []*big.Int {
+	p = p.orZero()
+	d := p.Deg()
+	lc := new(big.Int).Mod(p[d], m)
+	tmp, quo := new(big.Int), new(big.Int)
+	res := make([]*big.Int, len(xs))
+	for k, x := range xs {
+		ans := new(big.Int).Set(lc)
+		for i := d - 1; i >= 0; i-- {
+			tmp.Mul(ans, x)
+			tmp.Add(tmp, p[i])
+			quo.DivMod(tmp, m, ans)
+		}
+		res[k] = ans
+	}
+
+	return res
+}
+
+// EvalWithDeriv returns p(x) and p'(x) computed in one Horner pass
+func (p Poly) EvalWithDeriv(x, m *big.Int) (val, deriv *big.Int) {
+	p = p.orZero()
+	val = new(big.Int).Mod(p[p.Deg()], m)
+	deriv = new(big.Int)
+	for i := p.Deg() - 1; i >= 0; i-- {
+		deriv.Mul(deriv, x)
+		deriv.Add(deriv, val)
+		deriv.Mod(deriv, m)
+		val.Mul(val, x)
+		val.Add(val, p[i])
+		val.Mod(val, m)
+	}
+
+	return val, deriv
+}
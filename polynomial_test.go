@@ -3,9 +3,24 @@ package ecc
 import (
 	"fmt"
 	"math/big"
+	"math/rand"
 	"testing"
 )
 
+// checkCanonical fails the test unless p is trimmed and its coefficients are
+// in [0, m)
+func checkCanonical(t *testing.T, p Poly, m *big.Int) {
+	t.Helper()
+	for i, a := range p {
+		if a.Sign() < 0 || a.Cmp(m) >= 0 {
+			t.Errorf("%v: coefficient of x^%d is not in [0, %d)", p, i, m)
+		}
+	}
+	if len(p) > 1 && p[len(p)-1].Sign() == 0 {
+		t.Errorf("%v is not trimmed", p)
+	}
+}
+
 func TestPrettyPrint(t *testing.T) {
 	cases := []struct {
 		p   Poly
@@ -44,6 +59,66 @@ func TestPrettyPrint(t *testing.T) {
 	}
 }
 
+func TestLaTeX(t *testing.T) {
+	cases := []struct {
+		p   Poly
+		ans string
+	}{
+		{
+			NewPolyFromInt(0),
+			"0",
+		},
+		{
+			NewPolyFromInt(5, -4, 3, 3),
+			"3x^{3} + 3x^{2} - 4x + 5",
+		},
+		{
+			NewPolyFromInt(5, 6, 2),
+			"2x^{2} + 6x + 5",
+		},
+		{
+			NewPolyFromInt(5, -2, 0, 2, 1, 3),
+			"3x^{5} + x^{4} + 2x^{3} - 2x + 5",
+		},
+		{
+			NewPolyFromInt(2, 1, 0, -1, -2),
+			"-2x^{4} - x^{3} + x + 2",
+		},
+		{
+			NewPolyFromInt(1, 2, 2, 0, 1, 1),
+			"x^{5} + x^{4} + 2x^{2} + 2x + 1",
+		},
+		{
+			NewSparsePoly(map[int]*big.Int{12: big.NewInt(1)}),
+			"x^{12}",
+		},
+	}
+	for _, c := range cases {
+		if s := c.p.LaTeX(); s != c.ans {
+			t.Errorf("LaTeX %v should be %v", s, c.ans)
+		}
+	}
+}
+
+func TestCoeffs(t *testing.T) {
+	p := NewPolyFromInt(5, -4, 3, 3)
+	cs := p.Coeffs()
+	want := []int64{5, -4, 3, 3}
+	if len(cs) != len(want) {
+		t.Fatalf("got %d coefficients, want %d", len(cs), len(want))
+	}
+	for i, a := range cs {
+		if a.Int64() != want[i] {
+			t.Errorf("coefficient of x^%d: got: %v, want: %v", i, a, want[i])
+		}
+	}
+
+	cs[0].SetInt64(0)
+	if p[0].Int64() != 5 {
+		t.Errorf("Coeffs shares coefficients with the poly")
+	}
+}
+
 func TestAdd(t *testing.T) {
 	cases := []struct {
 		p   Poly
@@ -63,9 +138,16 @@ func TestAdd(t *testing.T) {
 			big.NewInt(4),
 			NewPolyFromInt(0, 0, 0, 3, 0, 1, 2),
 		},
+		{
+			NewPolyFromInt(1, -2, 9),
+			NewPolyFromInt(-3),
+			big.NewInt(7),
+			NewPolyFromInt(5, 5, 2),
+		},
 	}
 	for _, c := range cases {
 		res := (c.p).Add(c.q, c.m)
+		checkCanonical(t, res, c.m)
 		if res.Cmp(c.ans) != 0 {
 			t.Errorf("%v + %v != %v (your answer was %v)\n", c.p, c.q, c.ans, res)
 		}
@@ -104,6 +186,7 @@ func TestSub(t *testing.T) {
 	}
 	for _, c := range cases {
 		res := (c.p).Sub(c.q, c.m)
+		checkCanonical(t, res, c.m)
 		if res.Cmp(c.ans) != 0 {
 			t.Errorf("%v - %v != %v (your answer was %v)\n", c.p, c.q, c.ans, res)
 		}
@@ -142,6 +225,7 @@ func TestMultiply(t *testing.T) {
 	}
 	for _, c := range cases {
 		res := (c.p).Mul(c.q, c.m)
+		checkCanonical(t, res, c.m)
 		if res.Cmp(c.ans) != 0 {
 			t.Errorf("%v * %v != %v (your answer was %v)\n", c.p, c.q, c.ans, res)
 		}
@@ -158,49 +242,200 @@ func BenchmarkMultiply(b *testing.B) {
 	}
 }
 
-func TestDivide(t *testing.T) {
-	cases := []struct {
-		p, q     Poly
-		m        *big.Int
-		quo, rem Poly
-	}{
-		{
-			NewPolyFromInt(2, 0, 2, 1),
-			NewPolyFromInt(1, 0, 1),
-			big.NewInt(3),
-			NewPolyFromInt(2, 1),
-			NewPolyFromInt(0, 2),
-		},
-		{
-			NewPolyFromInt(5, 0, 0, 4, 7, 0, 3),
-			NewPolyFromInt(4, 0, 0, 3, 1),
-			big.NewInt(11),
-			NewPolyFromInt(1, 2, 3),
-			NewPolyFromInt(1, 3, 10, 1),
-		},
-		{
-			NewPolyFromInt(184, 187, 234, 0, 39, 245, 13, 268, 288, 250, 164, 0, 64, 258, 14, 113, 43, 161),
-			NewPolyFromInt(48, 0, 43, 22, 56, 84, 45, 67, 0, 34, 53),
-			big.NewInt(307),
-			NewPolyFromInt(98, 35, 0, 0, 23, 55, 44, 32),
-			NewPolyFromInt(85, 42, 11, 23, 45),
-		},
-		{
-			NewPolyFromInt(4, 0, 0, 1),
-			NewPolyFromInt(3, 1, 4, 1),
-			big.NewInt(7),
-			NewPolyFromInt(1),
-			NewPolyFromInt(1, 6, 3),
-		},
+func TestMulInto(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	m := big.NewInt(10007)
+	randPoly := func(deg int) Poly {
+		p := make(Poly, deg+1)
+		for i := range p {
+			p[i] = new(big.Int).Rand(rnd, m)
+		}
+		return p.trim()
 	}
-	for _, c := range cases {
+
+	var dst Poly
+	for _, deg := range [][2]int{{0, 0}, {5, 7}, {30, 20}, {2, 3}, {0, 40}, {12, 12}} {
+		p, q := randPoly(deg[0]), randPoly(deg[1])
+		pc, qc := p.TrimCopy(), q.TrimCopy()
+		want := p.Mul(q, m)
+		dst = p.MulInto(dst, q, m)
+		checkCanonical(t, dst, m)
+		if dst.Cmp(want) != 0 {
+			t.Errorf("%v * %v != %v (your answer was %v)", p, q, want, dst)
+		}
+		if p.Cmp(pc) != 0 || q.Cmp(qc) != 0 {
+			t.Errorf("inputs modified")
+		}
+	}
+
+	// zero product
+	dst = NewPolyFromInt(0).MulInto(dst, randPoly(10), m)
+	if !dst.isZero() || dst.Deg() != 0 {
+		t.Errorf("0 * Q != 0 (your answer was %v)", dst)
+	}
+}
+
+func BenchmarkMulInto(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	m := NextPrime(new(big.Int).Lsh(big.NewInt(1), 64))
+	p, q := make(Poly, 50), make(Poly, 50)
+	for i := range p {
+		p[i] = new(big.Int).Rand(rnd, m)
+		q[i] = new(big.Int).Rand(rnd, m)
+	}
+
+	b.Run("Mul", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.Mul(q, m)
+		}
+	})
+	b.Run("MulInto", func(b *testing.B) {
+		b.ReportAllocs()
+		var dst Poly
+		for i := 0; i < b.N; i++ {
+			dst = p.MulInto(dst, q, m)
+		}
+	})
+}
+
+func TestScaleInt(t *testing.T) {
+	m := big.NewInt(11)
+	for _, p := range []Poly{
+		NewPolyFromInt(0),
+		NewPolyFromInt(4, 0, 0, 3, 0, 1),
+		NewPolyFromInt(-7, 5, 10),
+	} {
+		for _, a := range []int64{0, 1, -1, 4, 11, 123456789} {
+			res := p.ScaleInt(big.NewInt(a), m)
+			checkCanonical(t, res, m)
+			if want := p.Mul(NewPolyFromInt(int(a)), m); res.Cmp(want) != 0 {
+				t.Errorf("%d * %v != %v (your answer was %v)", a, p, want, res)
+			}
+		}
+	}
+}
+
+func TestShiftLeft(t *testing.T) {
+	m := big.NewInt(11)
+	for _, p := range []Poly{
+		NewPolyFromInt(0),
+		NewPolyFromInt(3),
+		NewPolyFromInt(4, 0, 0, 3, 0, 1),
+	} {
+		for k := 0; k < 4; k++ {
+			res := p.ShiftLeft(k)
+			checkCanonical(t, res, m)
+			if want := p.Mul(NewSparsePoly(map[int]*big.Int{k: big.NewInt(1)}), m); res.Cmp(want) != 0 {
+				t.Errorf("x^%d * %v != %v (your answer was %v)", k, p, want, res)
+			}
+			if !p.isZero() && (res[k] == p[0] || res[k].Cmp(p[0]) != 0) {
+				t.Errorf("x^%d * %v shares the coefficients of P", k, p)
+			}
+		}
+	}
+}
+
+func BenchmarkShiftLeft(b *testing.B) {
+	p := NewPolyFromInt(4, 0, 0, 3, 0, 1, 7, 2, 9, 5)
+	b.Run("ShiftLeft", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.ShiftLeft(8)
+		}
+	})
+	b.Run("Clone", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.Clone(8)
+		}
+	})
+}
+
+func BenchmarkScaleInt(b *testing.B) {
+	p := NewPolyFromInt(4, 0, 0, 3, 0, 1)
+	a := big.NewInt(7)
+	m := big.NewInt(11)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.ScaleInt(a, m)
+	}
+}
+
+func BenchmarkScaleIntViaMul(b *testing.B) {
+	p := NewPolyFromInt(4, 0, 0, 3, 0, 1)
+	m := big.NewInt(11)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Mul(NewPolyFromInt(7), m)
+	}
+}
+
+var divideCases = []struct {
+	p, q     Poly
+	m        *big.Int
+	quo, rem Poly
+}{
+	{
+		NewPolyFromInt(2, 0, 2, 1),
+		NewPolyFromInt(1, 0, 1),
+		big.NewInt(3),
+		NewPolyFromInt(2, 1),
+		NewPolyFromInt(0, 2),
+	},
+	{
+		NewPolyFromInt(5, 0, 0, 4, 7, 0, 3),
+		NewPolyFromInt(4, 0, 0, 3, 1),
+		big.NewInt(11),
+		NewPolyFromInt(1, 2, 3),
+		NewPolyFromInt(1, 3, 10, 1),
+	},
+	{
+		NewPolyFromInt(184, 187, 234, 0, 39, 245, 13, 268, 288, 250, 164, 0, 64, 258, 14, 113, 43, 161),
+		NewPolyFromInt(48, 0, 43, 22, 56, 84, 45, 67, 0, 34, 53),
+		big.NewInt(307),
+		NewPolyFromInt(98, 35, 0, 0, 23, 55, 44, 32),
+		NewPolyFromInt(85, 42, 11, 23, 45),
+	},
+	{
+		NewPolyFromInt(4, 0, 0, 1),
+		NewPolyFromInt(3, 1, 4, 1),
+		big.NewInt(7),
+		NewPolyFromInt(1),
+		NewPolyFromInt(1, 6, 3),
+	},
+}
+
+func TestDivide(t *testing.T) {
+	for _, c := range divideCases {
 		q, r := (c.p).Div(c.q, c.m)
+		checkCanonical(t, q, c.m)
+		checkCanonical(t, r, c.m)
 		if q.Cmp(c.quo) != 0 || r.Cmp(c.rem) != 0 {
 			t.Errorf("%v / %v != %v (%v) (your answer was %v (%v))\n", c.p, c.q, c.quo, c.rem, q, r)
 		}
 	}
 }
 
+func TestDivExact(t *testing.T) {
+	m := big.NewInt(13)
+	p := NewPolyFromInt(3, 0, 3).Mul(NewPolyFromInt(4, 5, 6, 7), m)
+	q, err := p.DivExact(NewPolyFromInt(3, 0, 3), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Cmp(NewPolyFromInt(4, 5, 6, 7)) != 0 {
+		t.Errorf("got: %v, want: %v", q, NewPolyFromInt(4, 5, 6, 7))
+	}
+
+	if _, err := p.Add(NewPolyFromInt(1), m).DivExact(NewPolyFromInt(3, 0, 3), m); err != ErrInexactDivision {
+		t.Errorf("got: %v, want: %v", err, ErrInexactDivision)
+	}
+	if _, err := p.DivExact(NewPolyFromInt(0), m); err != ErrZeroDivision {
+		t.Errorf("got: %v, want: %v", err, ErrZeroDivision)
+	}
+}
+
 func TestExp(t *testing.T) {
 	cases := []struct {
 		p   Poly
@@ -229,18 +464,66 @@ func TestExp(t *testing.T) {
 	}
 	for _, c := range cases {
 		res := c.p.Exp(c.e, c.m)
+		checkCanonical(t, res, c.m)
 		if res.Cmp(c.ans) != 0 {
 			t.Errorf("%v^%v != %v (your answer was %v)\n", c.p, c.e, c.ans, res)
 		}
 	}
 }
 
+func TestExpWindow(t *testing.T) {
+	m := big.NewInt(10007)
+	p := NewPolyFromInt(3, -1, 4, 1, -5)
+	// every window width, exponents with runs of zeros and ones, and negative
+	// exponents, whose sign Exp ignores
+	var es []*big.Int
+	for _, e := range []int64{0, 1, 2, 3, 5, 8, 255, 256, 0x1ff, 0xabc, 0x10001, 0xf0f0f0, 1<<40 + 1, -3, -0x1ff} {
+		es = append(es, big.NewInt(e))
+	}
+	es = append(es, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 300), big.NewInt(1)))
+	for _, e := range es {
+		for _, p := range []Poly{p, NewPolyFromInt(1, 1), NewPolyFromInt(0), NewPolyFromInt(7)} {
+			if e.BitLen() > 10 && p.Deg() > 0 {
+				continue // too large to expand
+			}
+			want := p.Exp(e, m)
+			got := p.ExpWindow(e, m)
+			checkCanonical(t, got, m)
+			if got.Cmp(want) != 0 {
+				t.Errorf("%v^%d != %v (your answer was %v)", p, e, want, got)
+			}
+		}
+	}
+}
+
+func BenchmarkExpWindow(b *testing.B) {
+	m := NextPrime(new(big.Int).Lsh(big.NewInt(1), 64))
+	p := NewPolyFromInt(3, -1, 4, 1, -5, 9, 2, -6, 5)
+	e := big.NewInt(0x5d)
+
+	b.Run("Exp", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.Exp(e, m)
+		}
+	})
+	b.Run("ExpWindow", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.ExpWindow(e, m)
+		}
+	})
+}
+
 func TestDeriv(t *testing.T) {
 	cases := []struct {
 		p   Poly
 		m   *big.Int
 		ans Poly
 	}{
+		{
+			NewPolyFromInt(1, -1, -1),
+			big.NewInt(7),
+			NewPolyFromInt(6, 5),
+		},
 		{
 			NewPolyFromInt(5),
 			big.NewInt(7),
@@ -265,6 +548,7 @@ func TestDeriv(t *testing.T) {
 
 	for _, c := range cases {
 		res := c.p.Deriv(c.m)
+		checkCanonical(t, res, c.m)
 		if res.Cmp(c.ans) != 0 {
 			t.Errorf("Deriv(%v) != %v (your answer was %v)\n", c.p, c.ans, res)
 		}
@@ -299,6 +583,7 @@ func TestPolyGCD(t *testing.T) {
 	}
 	for _, c := range cases {
 		res := (c.p).GCD(c.q, c.m)
+		checkCanonical(t, res, c.m)
 		if res.Cmp(c.ans) != 0 {
 			t.Errorf("GCD(%v, %v) != %v (your answer was %v)\n", c.p, c.q, c.ans, res)
 		}
@@ -339,6 +624,7 @@ func TestModInverse(t *testing.T) {
 	}
 	for _, c := range cases {
 		q := c.p.ModInverse(c.h, c.m)
+		checkCanonical(t, q, c.m)
 		if q.Cmp(c.ans) != 0 {
 			t.Errorf("ModInverse got %v != want %v", q, c.ans)
 		}
@@ -362,6 +648,12 @@ func TestEval(t *testing.T) {
 			big.NewInt(2),
 			big.NewInt(0),
 		},
+		{
+			NewPolyFromInt(-3),
+			big.NewInt(1),
+			big.NewInt(7),
+			big.NewInt(4),
+		},
 		{
 			NewPolyFromInt(6, 2, 0, 4, 1),
 			big.NewInt(2),
@@ -382,3 +674,386 @@ func TestEval(t *testing.T) {
 		}
 	}
 }
+
+func TestEvalMulti(t *testing.T) {
+	p := NewPolyFromInt(45545, 343424, 5545, 3445435, 0, 343434, 4665, 5452, 34344, 534556, 4345345, 5656, 434525, 53333, 36645)
+	m := big.NewInt(1046527)
+	xs := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(-5), big.NewInt(394), big.NewInt(2000000)}
+	res := p.EvalMulti(xs, m)
+	if len(res) != len(xs) {
+		t.Fatalf("got: %d results, want: %d", len(res), len(xs))
+	}
+	for i, x := range xs {
+		if want := p.Eval(x, m); res[i].Cmp(want) != 0 {
+			t.Errorf("poly(%v): got: %v, want: %v", x, res[i], want)
+		}
+	}
+
+	if res := NewPolyFromInt(-3).EvalMulti(xs[:2], big.NewInt(7)); res[0].Int64() != 4 || res[1].Int64() != 4 {
+		t.Errorf("got: %v, want: [4 4]", res)
+	}
+}
+
+func TestEvalWithDeriv(t *testing.T) {
+	cases := []struct {
+		p Poly
+		m *big.Int
+	}{
+		{NewPolyFromInt(0), big.NewInt(7)},
+		{NewPolyFromInt(-3), big.NewInt(7)},
+		{NewPolyFromInt(6, 2, 0, 4, 1), big.NewInt(10)},
+		{NewPolyFromInt(45545, 343424, 5545, 3445435, 0, 343434, 4665, 5452, 34344, 534556, 4345345, 5656, 434525, 53333, 36645), big.NewInt(1046527)},
+		// p^k moduli, as in Hensel lifting
+		{NewPolyFromInt(75, 1001, 0, 1), big.NewInt(7919 * 7919)},
+	}
+	for _, c := range cases {
+		for _, x := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(-2), big.NewInt(394)} {
+			val, deriv := c.p.EvalWithDeriv(x, c.m)
+			if want := c.p.Eval(x, c.m); val.Cmp(want) != 0 {
+				t.Errorf("%v at %v: got: %v, want: %v", c.p, x, val, want)
+			}
+			if want := c.p.Deriv(c.m).Eval(x, c.m); deriv.Cmp(want) != 0 {
+				t.Errorf("%v' at %v: got: %v, want: %v", c.p, x, deriv, want)
+			}
+		}
+	}
+}
+
+func BenchmarkEvalMulti(b *testing.B) {
+	p := NewPolyFromInt(45545, 343424, 5545, 3445435, 0, 343434, 4665, 5452, 34344, 534556, 4345345, 5656, 434525, 53333, 36645)
+	m := big.NewInt(1046527)
+	xs := make([]*big.Int, 1000)
+	for i := range xs {
+		xs[i] = big.NewInt(int64(i))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.EvalMulti(xs, m)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		p, q Poly
+		ans  bool
+	}{
+		{NewPolyFromInt(0), NewPolyFromInt(0), true},
+		{NewPolyFromInt(1, 2, 3), NewPolyFromInt(1, 2, 3), true},
+		{NewPolyFromInt(1, 2, 3), NewPolyFromInt(1, 2, 4), false},
+		{NewPolyFromInt(1, 2), NewPolyFromInt(1, 2, 3), false},
+		{NewPolyFromInt(1, 2, 0, 0), NewPolyFromInt(1, 2), true},
+		{NewPolyFromInt(0, 0), NewPolyFromInt(0), true},
+	}
+	for _, c := range cases {
+		if c.p.Equal(c.q) != c.ans || c.q.Equal(c.p) != c.ans {
+			t.Errorf("Equal(%v, %v) != %v", c.p, c.q, c.ans)
+		}
+	}
+}
+
+func TestIsConstant(t *testing.T) {
+	cases := []struct {
+		p   Poly
+		ans bool
+	}{
+		{NewPolyFromInt(0), true},
+		{NewPolyFromInt(5), true},
+		{NewPolyFromInt(5, 0, 0), true},
+		{NewPolyFromInt(5, 1), false},
+		{NewPolyFromInt(0, 0, 1, 0), false},
+	}
+	for _, c := range cases {
+		if c.p.IsConstant() != c.ans {
+			t.Errorf("IsConstant(%v) != %v", c.p, c.ans)
+		}
+	}
+}
+
+func TestIsOne(t *testing.T) {
+	cases := []struct {
+		p   Poly
+		ans bool
+	}{
+		{NewPolyFromInt(1), true},
+		{NewPolyFromInt(1, 0, 0), true},
+		{NewPolyFromInt(0), false},
+		{NewPolyFromInt(2), false},
+		{NewPolyFromInt(1, 1), false},
+		{NewPolyFromInt(-1), false},
+	}
+	for _, c := range cases {
+		if c.p.IsOne() != c.ans {
+			t.Errorf("IsOne(%v) != %v", c.p, c.ans)
+		}
+	}
+}
+
+func TestNewPolyFromInt64(t *testing.T) {
+	a := []int64{-1 << 40, 0, 1<<31 + 7, -(1<<31 + 1), 1<<63 - 1}
+	p := NewPolyFromInt64(a...)
+	if p.Deg() != len(a)-1 {
+		t.Fatalf("got degree %d, want: %d", p.Deg(), len(a)-1)
+	}
+	for i, c := range a {
+		if p[i].Int64() != c || !p[i].IsInt64() {
+			t.Errorf("coefficient %d got: %v, want: %d", i, p[i], c)
+		}
+	}
+
+	want := NewPolyFromBigInt(big.NewInt(1<<31+7), big.NewInt(-(1 << 40)))
+	if got := NewPolyFromInt64(1<<31+7, -(1 << 40)); got.Cmp(want) != 0 {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestNewSparsePoly(t *testing.T) {
+	cases := []struct {
+		terms map[int]*big.Int
+		s     string
+	}{
+		{
+			map[int]*big.Int{},
+			"[0]",
+		},
+		{
+			map[int]*big.Int{101: big.NewInt(1), 0: big.NewInt(3)},
+			"[x^101 + 3]",
+		},
+		{
+			map[int]*big.Int{5: big.NewInt(2), 2: big.NewInt(-1), 7: big.NewInt(0)},
+			"[2x^5 - x^2]",
+		},
+	}
+	for _, c := range cases {
+		p := NewSparsePoly(c.terms)
+		if p.String() != c.s {
+			t.Errorf("got: %v, want: %v", p, c.s)
+		}
+	}
+
+	// 2^101 + 3 mod 1000003
+	m := big.NewInt(1000003)
+	x := big.NewInt(2)
+	want := new(big.Int).Exp(x, big.NewInt(101), m)
+	want.Add(want, big.NewInt(3)).Mod(want, m)
+	p := NewSparsePoly(map[int]*big.Int{101: big.NewInt(1), 0: big.NewInt(3)})
+	if got := p.Eval(x, m); got.Cmp(want) != 0 {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestTrimCopy(t *testing.T) {
+	p := NewPolyFromInt(1, 2, 3, 0, 0)
+	q := p.TrimCopy()
+	if q.Cmp(NewPolyFromInt(1, 2, 3)) != 0 {
+		t.Errorf("got: %v, want: [3x^2 + 2x + 1]", q)
+	}
+
+	p[0].SetInt64(7)
+	p[2].SetInt64(9)
+	if q.Cmp(NewPolyFromInt(1, 2, 3)) != 0 {
+		t.Errorf("copy changed with the original: %v", q)
+	}
+}
+
+func TestContent(t *testing.T) {
+	for _, c := range []struct {
+		p       Poly
+		content int64
+		pp      Poly
+	}{
+		{NewPolyFromInt(0), 0, NewPolyFromInt(0)},
+		{NewPolyFromInt(5), 5, NewPolyFromInt(1)},
+		{NewPolyFromInt(-5), -5, NewPolyFromInt(1)},
+		{NewPolyFromInt(2, 3), 1, NewPolyFromInt(2, 3)},
+		{NewPolyFromInt(4, 0, -6), -2, NewPolyFromInt(-2, 0, 3)},
+		{NewPolyFromInt(12, 18, 0, 30, 0, 0), 6, NewPolyFromInt(2, 3, 0, 5)},
+		{NewPolyFromInt(-14, 21, 0, -7), -7, NewPolyFromInt(2, -3, 0, 1)},
+	} {
+		if got := c.p.Content(); got.Int64() != c.content {
+			t.Errorf("content of %v got: %v, want: %d", c.p, got, c.content)
+		}
+		if got := c.p.PrimitivePart(); got.Cmp(c.pp) != 0 {
+			t.Errorf("primitive part of %v got: %v, want: %v", c.p, got, c.pp)
+		}
+	}
+
+	// Content(P)·PrimitivePart(P) = P, and scaling doesn't change the primitive part
+	p := NewPolyFromInt(3, -1, 4, 1, -5, 9)
+	k := BigFromDecimal("123456789012345678901234567890")
+	kp := make(Poly, len(p))
+	for i := range p {
+		kp[i] = new(big.Int).Mul(p[i], k)
+	}
+	if got := kp.Content(); got.Cmp(k) != 0 {
+		t.Errorf("got: %v, want: %v", got, k)
+	}
+	if got := kp.PrimitivePart(); got.Cmp(p) != 0 {
+		t.Errorf("got: %v, want: %v", got, p)
+	}
+	if kp[0].Cmp(new(big.Int).Mul(p[0], k)) != 0 {
+		t.Errorf("PrimitivePart changed its receiver: %v", kp)
+	}
+}
+
+func TestDivDoesNotMutate(t *testing.T) {
+	p := NewPolyFromInt(-1, 0, 0, 13)
+	q := NewPolyFromInt(1, 1)
+	p.Div(q, big.NewInt(7))
+	if p.Cmp(NewPolyFromInt(-1, 0, 0, 13)) != 0 {
+		t.Errorf("dividend changed: %v", p)
+	}
+}
+
+// polyFromBytes reads coefficients as big-endian int16s
+func polyFromBytes(b []byte) Poly {
+	p := NewPolyFromInt(0)
+	for i := 0; i+1 < len(b); i += 2 {
+		a := int(int16(uint16(b[i])<<8 | uint16(b[i+1])))
+		if i == 0 {
+			p[0].SetInt64(int64(a))
+		} else {
+			p = append(p, big.NewInt(int64(a)))
+		}
+	}
+	return p
+}
+
+func polyToBytes(p Poly) []byte {
+	var b []byte
+	for _, a := range p {
+		v := uint16(int16(a.Int64()))
+		b = append(b, byte(v>>8), byte(v))
+	}
+	return b
+}
+
+func FuzzPolyDiv(f *testing.F) {
+	for _, c := range divideCases {
+		f.Add(polyToBytes(c.p), polyToBytes(c.q), c.m.Int64())
+	}
+	f.Add([]byte{0, 1}, []byte{0, 0}, int64(7))
+	f.Add([]byte{0, 1, 0, 2}, []byte{0, 3, 0, 2}, int64(4))
+
+	f.Fuzz(func(t *testing.T, pb, qb []byte, m int64) {
+		p, q := polyFromBytes(pb), polyFromBytes(qb)
+		if len(p) > 64 || len(q) > 64 {
+			return
+		}
+		mod := big.NewInt(m)
+		quo, rem := p.Div(q, mod)
+		if quo == nil {
+			return
+		}
+		if rem.Deg() >= q.TrimCopy().sanitize(mod).Deg() && !rem.isZero() {
+			t.Errorf("deg(%v) >= deg(%v)", rem, q)
+		}
+		got := quo.Mul(q, mod).Add(rem, mod)
+		want := p.TrimCopy().sanitize(mod)
+		if !got.Equal(want) {
+			t.Errorf("%v * %v + %v = %v, want %v (mod %d)", quo, q, rem, got, want, m)
+		}
+	})
+}
+
+func TestRoots(t *testing.T) {
+	m := big.NewInt(10007)
+	want := []int64{0, 1, 17, 5000, 10006}
+	p := NewPolyFromInt(1)
+	for _, r := range want {
+		p = p.Mul(NewPolyFromInt(-int(r), 1), m)
+	}
+	// a repeated root and an irreducible quadratic don't add roots
+	p = p.Mul(NewPolyFromInt(-17, 1), m)
+	p = p.Mul(NewPolyFromInt(1, 0, 1), m) // -1 is not a square mod 10007
+	p = p.ScaleInt(big.NewInt(3), m)
+
+	got := p.Roots(m)
+	if len(got) != len(want) {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+	for i := range want {
+		if got[i].Int64() != want[i] {
+			t.Errorf("got: %v, want: %v", got, want)
+			break
+		}
+	}
+
+	for _, c := range []struct {
+		p    Poly
+		m    int64
+		want int
+	}{
+		{NewPolyFromInt(0), 7, 0},
+		{NewPolyFromInt(3), 7, 0},
+		{NewPolyFromInt(1, 0, 1), 7, 0},
+		{NewPolyFromInt(1, 0, 1), 5, 2},
+		{NewPolyFromInt(0, 1, 1), 2, 2},
+		{NewPolyFromInt(1, 1, 1), 2, 0},
+	} {
+		if got := c.p.Roots(big.NewInt(c.m)); len(got) != c.want {
+			t.Errorf("roots of %v mod %d got: %v, want: %d of them", c.p, c.m, got, c.want)
+		}
+	}
+}
+
+func TestEmptyPoly(t *testing.T) {
+	m := big.NewInt(7)
+	zero := NewPolyFromInt(0)
+	p := NewPolyFromInt(1, 2, 3)
+	check := func(name string, got Poly, want Poly) {
+		t.Helper()
+		if len(got) == 0 || !got.Equal(want) {
+			t.Errorf("%s: got: %v, want: %v", name, got, want)
+		}
+	}
+
+	for _, e := range []Poly{nil, {}} {
+		check("NewPolyFromInt()", NewPolyFromInt(), zero)
+		check("NewPolyFromInt64()", NewPolyFromInt64(), zero)
+		check("NewPolyFromBigInt()", NewPolyFromBigInt(), zero)
+		if e.Deg() != 0 || !e.isZero() || e.String() != "[0]" {
+			t.Errorf("%#v: got Deg %d, isZero %v, %v", e, e.Deg(), e.isZero(), e)
+		}
+		if e.Cmp(zero) != 0 || zero.Cmp(e) != 0 || !e.Equal(zero) {
+			t.Errorf("%#v is not equal to 0", e)
+		}
+
+		check("e + e", e.Add(e, m), zero)
+		check("e + p", e.Add(p, m), p)
+		check("p + e", p.Add(e, m), p)
+		check("e - p", e.Sub(p, m), p.Neg().sanitize(m))
+		check("p - e", p.Sub(e, m), p)
+		check("e * p", e.Mul(p, m), zero)
+		check("p * e", p.Mul(e, m), zero)
+		check("e * e", e.Mul(e, m), zero)
+		check("-e", e.Neg(), zero)
+		check("3e", e.ScaleInt(big.NewInt(3), m), zero)
+		check("e^0", e.Exp(big.NewInt(0), m), NewPolyFromInt(1))
+		check("e^3", e.Exp(big.NewInt(3), m), zero)
+		check("e^3 window", e.ExpWindow(big.NewInt(3), m), zero)
+		check("clone", e.Clone(2), zero)
+		check("trim", e.TrimCopy(), zero)
+		check("e'", e.Deriv(m), zero)
+		check("gcd(e, p)", e.GCD(p, m), p.Monic(m))
+		check("monic", e.Monic(m), zero)
+
+		quo, rem := e.Div(p, m)
+		check("e / p", quo, zero)
+		check("e % p", rem, zero)
+		if quo, rem := p.Div(e, m); quo != nil || rem != nil {
+			t.Errorf("p / e: got: %v, %v, want: nil, nil", quo, rem)
+		}
+
+		if v := e.Eval(big.NewInt(3), m); v.Sign() != 0 {
+			t.Errorf("e(3): got: %d, want: 0", v)
+		}
+		if v, d := e.EvalWithDeriv(big.NewInt(3), m); v.Sign() != 0 || d.Sign() != 0 {
+			t.Errorf("e(3), e'(3): got: %d, %d, want: 0, 0", v, d)
+		}
+		if vs := e.EvalMulti([]*big.Int{big.NewInt(1), big.NewInt(2)}, m); len(vs) != 2 || vs[0].Sign() != 0 || vs[1].Sign() != 0 {
+			t.Errorf("e(1), e(2): got: %v, want: [0 0]", vs)
+		}
+	}
+}
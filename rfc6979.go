@@ -0,0 +1,65 @@
+package ecc
+
+import (
+	"crypto/hmac"
+	"hash"
+	"math/big"
+)
+
+// bits2octets converts a hash into a scalar modulo N, encoded in OrderBytes
+// bytes, as in RFC 6979, Section 2.3.4.
+func (c *Curve) bits2octets(hash []byte) []byte {
+	z := c.hashToInt(hash)
+	z.Mod(z, c.N)
+	return z.FillBytes(make([]byte, c.OrderBytes()))
+}
+
+// nonceRFC6979 returns a generator of the nonces k of RFC 6979, Section 3.2,
+// for signing hash with priv, using HMAC with h. Each call returns the next
+// candidate, for when a nonce gives r = 0 or s = 0.
+func (c *Curve) nonceRFC6979(priv *big.Int, hash []byte, h func() hash.Hash) func() *big.Int {
+	rolen := c.OrderBytes()
+	x := priv.FillBytes(make([]byte, rolen))
+	h1 := c.bits2octets(hash)
+
+	hlen := h().Size()
+	V := make([]byte, hlen)
+	for i := range V {
+		V[i] = 0x01
+	}
+	K := make([]byte, hlen)
+
+	mac := func(key []byte, data ...[]byte) []byte {
+		m := hmac.New(h, key)
+		for _, d := range data {
+			m.Write(d)
+		}
+		return m.Sum(nil)
+	}
+
+	K = mac(K, V, []byte{0x00}, x, h1)
+	V = mac(K, V)
+	K = mac(K, V, []byte{0x01}, x, h1)
+	V = mac(K, V)
+
+	first := true
+	return func() *big.Int {
+		for {
+			if !first {
+				K = mac(K, V, []byte{0x00})
+				V = mac(K, V)
+			}
+			first = false
+
+			var T []byte
+			for len(T) < rolen {
+				V = mac(K, V)
+				T = append(T, V...)
+			}
+			k := c.hashToInt(T)
+			if k.Sign() > 0 && k.Cmp(c.N) < 0 {
+				return k
+			}
+		}
+	}
+}
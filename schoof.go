@@ -25,12 +25,15 @@ type Qring struct {
 }
 
 // Endo is the Frobenius endomorphism
+// the identity of the group law (the point at infinity) has inf set
 type Endo struct {
 	qr   *Qring
 	x, y Poly
+	inf  bool
 }
 
 type Trace struct {
+	ell *big.Int
 	tr  *big.Int
 	err error
 }
@@ -43,43 +46,98 @@ var (
 	ErrNoCharacterPoly = errors.New("frobenius satisfies no character poly")
 )
 
-func (qr *Qring) poly(p Poly) Poly {
+// NewQring returns the quotient ring F_q[x]/(h)
+func NewQring(h Poly, q *big.Int) *Qring {
+	return &Qring{h.TrimCopy().sanitize(q), q}
+}
+
+// Reduce returns the representative of P in F_q[x]/(h) of degree less than h
+func (qr *Qring) Reduce(p Poly) Poly {
 	_, r := p.Div(qr.h, qr.q)
 	return r
 }
 
+// Mul returns P * Q in F_q[x]/(h)
+func (qr *Qring) Mul(p, q Poly) Poly {
+	return qr.Reduce(p.Mul(q, qr.q))
+}
+
+// Exp returns P^e in F_q[x]/(h)
+func (qr *Qring) Exp(p Poly, e *big.Int) Poly {
+	r := NewPolyFromInt(1)
+	// Reduce copies its input, so one product buffer serves every step
+	var buf Poly
+
+	for _, b := range e.Bytes() {
+		for bitNum := 0; bitNum < 8; bitNum++ {
+			buf = r.MulInto(buf, r, qr.q)
+			r = qr.Reduce(buf)
+			if b&0x80 == 0x80 {
+				buf = r.MulInto(buf, p, qr.q)
+				r = qr.Reduce(buf)
+			}
+			b <<= 1
+		}
+	}
+
+	return qr.Reduce(r)
+}
+
+// Inverse returns P^-1 in F_q[x]/(h)
+// it is an error if P and h are not coprime
+func (qr *Qring) Inverse(p Poly) (Poly, error) {
+	inv := qr.Reduce(p).ModInverse(qr.h, qr.q)
+	if inv == nil {
+		return nil, ErrZeroDivision
+	}
+	return qr.Reduce(inv), nil
+}
+
 func NewEnd(qr *Qring, x, y Poly) *Endo {
 	return &Endo{
 		qr: qr,
-		x:  qr.poly(x),
-		y:  qr.poly(y),
+		x:  qr.Reduce(x),
+		y:  qr.Reduce(y),
 	}
 }
 
+// Identity returns the identity of the group law in End(E[ell])
+func Identity(qr *Qring) *Endo {
+	return &Endo{qr: qr, inf: true}
+}
+
+// IsIdentity checks if P is the identity
+func (pe *Endo) IsIdentity() bool {
+	return pe.inf
+}
+
 func Eq(pe, qe *Endo) bool {
-	return pe.x.Cmp(qe.x) == 0 && pe.y.Cmp(qe.y) == 0
+	if pe.inf || qe.inf {
+		return pe.inf == qe.inf
+	}
+	return pe.x.Equal(qe.x) && pe.y.Equal(qe.y)
 }
 
 // Add endomorphisms P and Q in End(E[ell])
 func Add(pe, qe *Endo, A *big.Int, f Poly) (*Endo, error) {
-	if pe == nil {
+	if pe.inf {
 		return qe, nil
 	}
-	if qe == nil {
+	if qe.inf {
 		return pe, nil
 	}
 
 	h, q := pe.qr.h, pe.qr.q
-	qpoly := pe.qr.poly
+	qpoly := pe.qr.Reduce
 
 	a1, b1 := pe.x, pe.y
 	a2, b2 := qe.x, qe.y
 
-	if a1.Cmp(a2) == 0 {
-		if b1.Cmp(b2) == 0 {
+	if a1.Equal(a2) {
+		if b1.Equal(b2) {
 			return Double(pe, A, f)
 		}
-		return nil, nil
+		return Identity(pe.qr), nil
 	}
 
 	b := b2.Sub(b1, q)
@@ -100,12 +158,12 @@ func Add(pe, qe *Endo, A *big.Int, f Poly) (*Endo, error) {
 
 // Double the endomorphism P in End(E[ell])
 func Double(pe *Endo, A *big.Int, f Poly) (*Endo, error) {
-	if pe == nil {
-		return nil, nil
+	if pe.inf {
+		return pe, nil
 	}
 
 	h, q := pe.qr.h, pe.qr.q
-	qpoly := pe.qr.poly
+	qpoly := pe.qr.Reduce
 
 	a1, b1 := pe.x, pe.y
 	m := qpoly(a1.Mul(a1, q))
@@ -128,40 +186,37 @@ func Double(pe *Endo, A *big.Int, f Poly) (*Endo, error) {
 
 // Neg negate the endomorphism P in End(E[ell])
 func Neg(pe *Endo) *Endo {
-	if pe == nil {
-		return nil
+	if pe.inf {
+		return pe
 	}
 
 	return NewEnd(pe.qr, pe.x, pe.y.Neg())
 }
 
-// ScalarMul compute the scalar multiple n*P in End(E[ell]) using double and Add
+// ScalarMul compute the scalar multiple n*P in End(E[ell]) using the NAF of n
 func ScalarMul(pe *Endo, n *big.Int, A *big.Int, f Poly) (*Endo, error) {
 	var err error
 
 	if n == nil {
-		return nil, nil
+		return Identity(pe.qr), nil
 	}
 
-	re := NewEnd(pe.qr, pe.x, pe.y)
-	for i, b := range n.Bytes() {
-		j := 0
-		if i == 0 {
-			for j = 1; b&0x80 != 0x80; j++ {
-				b <<= 1
-			}
-			b <<= 1
+	ne := Neg(pe)
+	digits := naf(n)
+
+	re := Identity(pe.qr)
+	for i := len(digits) - 1; i >= 0; i-- {
+		if re, err = Double(re, A, f); err != nil {
+			return nil, err
 		}
-		for bitNum := j; bitNum < 8; bitNum++ {
-			if re, err = Double(re, A, f); err != nil {
-				return nil, err
-			}
-			if b&0x80 == 0x80 {
-				if re, err = Add(re, pe, A, f); err != nil {
-					return nil, err
-				}
-			}
-			b <<= 1
+		switch digits[i] {
+		case 1:
+			re, err = Add(re, pe, A, f)
+		case -1:
+			re, err = Add(re, ne, A, f)
+		}
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -187,20 +242,7 @@ func Square(pe *Endo, f Poly) *Endo {
 }
 
 func Exp(qr *Qring, p Poly, e *big.Int) Poly {
-	qpoly := qr.poly
-	r := NewPolyFromInt(1)
-
-	for _, b := range e.Bytes() {
-		for bitNum := 0; bitNum < 8; bitNum++ {
-			r = qpoly(r.Mul(r, qr.q))
-			if b&0x80 == 0x80 {
-				r = qpoly(r.Mul(p, qr.q))
-			}
-			b <<= 1
-		}
-	}
-
-	return r
+	return qr.Exp(p, e)
 }
 
 func Irreducible(qr *Qring) bool {
@@ -208,11 +250,17 @@ func Irreducible(qr *Qring) bool {
 	x := NewPolyFromInt(0, 1)
 	xq := Exp(qr, x, q).Sub(x, q)
 
-	return xq.GCD(h, q).Cmp(NewPolyFromInt(1)) == 0
+	return xq.GCD(h, q).IsOne()
 }
 
 // TraceMod computes the Trace of Frobenius of E modulo ell
 func TraceMod(c *Curve, ell *big.Int) <-chan interface{} {
+	return traceMod(c, ell, Exp)
+}
+
+// traceMod is TraceMod with frobeniusExp computing the Frobenius images, so
+// that tests can count the calls.
+func traceMod(c *Curve, ell *big.Int, frobeniusExp func(qr *Qring, p Poly, e *big.Int) Poly) <-chan interface{} {
 	ch := make(chan interface{})
 
 	go func() {
@@ -224,14 +272,15 @@ func TraceMod(c *Curve, ell *big.Int) <-chan interface{} {
 
 		if ell.Cmp(big.NewInt(2)) == 0 {
 			if Irreducible(&Qring{f, q}) {
-				ch <- &Trace{big.NewInt(1), nil}
+				ch <- &Trace{ell, big.NewInt(1), nil}
 				return
 			}
-			ch <- &Trace{big.NewInt(0), nil}
+			ch <- &Trace{ell, big.NewInt(0), nil}
 			return
 		}
 
 		var err error
+		var xq, yq Poly
 		for {
 			switch err {
 			case ErrZeroDivision:
@@ -239,12 +288,18 @@ func TraceMod(c *Curve, ell *big.Int) <-chan interface{} {
 				log.Printf("found %d-DivPoly factor of degree %d\n",
 					ell, qr.h.Deg())
 			case ErrNoCharacterPoly:
-				ch <- &Trace{nil, err}
+				ch <- &Trace{ell, nil, err}
 				return
 			}
 
-			xq := Exp(qr, NewPolyFromInt(0, 1), q)
-			yq := Exp(qr, f, new(big.Int).Div(q, big.NewInt(2)))
+			if xq == nil {
+				xq = frobeniusExp(qr, NewPolyFromInt(0, 1), q)
+				yq = frobeniusExp(qr, f, new(big.Int).Div(q, big.NewInt(2)))
+			} else {
+				// the new h divides the old one, so the images modulo the
+				// old h reduce to the images modulo the new one
+				xq, yq = qr.Reduce(xq), qr.Reduce(yq)
+			}
 			pi := NewEnd(qr, xq, yq)
 			pi2 := Square(pi, f)
 
@@ -257,16 +312,16 @@ func TraceMod(c *Curve, ell *big.Int) <-chan interface{} {
 				continue
 			}
 
-			if S == nil {
-				ch <- &Trace{big.NewInt(0), nil}
+			if S.IsIdentity() {
+				ch <- &Trace{ell, big.NewInt(0), nil}
 				return
 			}
 			if Eq(S, pi) {
-				ch <- &Trace{big.NewInt(1), nil}
+				ch <- &Trace{ell, big.NewInt(1), nil}
 				return
 			}
 			if Eq(Neg(S), pi) {
-				ch <- &Trace{big.NewInt(-1), nil}
+				ch <- &Trace{ell, big.NewInt(-1), nil}
 				return
 			}
 
@@ -276,7 +331,7 @@ func TraceMod(c *Curve, ell *big.Int) <-chan interface{} {
 					break
 				}
 				if Eq(P, S) {
-					ch <- &Trace{big.NewInt(t), nil}
+					ch <- &Trace{ell, big.NewInt(t), nil}
 					return
 				}
 			}
@@ -287,9 +342,55 @@ func TraceMod(c *Curve, ell *big.Int) <-chan interface{} {
 }
 
 // Schoof computes the Trace of Frobenius of E(Elliptic curve)
+// For j = 0 or j = 1728 curves the order is found directly from the
+// complex multiplication by Z[ω] or Z[i], falling back to the generic
+// algorithm if that fails. SchoofWithProgress, SchoofWithTrace and
+// SchoofParallel always run the generic algorithm.
 func (c *Curve) Schoof() (*big.Int, error) {
+	if n, ok := c.cmOrder(); ok {
+		return n, nil
+	}
+	return c.SchoofWithProgress(nil)
+}
+
+// SchoofWithProgress is like Schoof, but calls cb, if not nil, each time the
+// Trace modulo a prime is found. cb is called from the calling goroutine.
+func (c *Curve) SchoofWithProgress(cb func(primesDone, primesTotal int, currentPrime int64)) (*big.Int, error) {
+	if cb == nil {
+		return c.schoof(0, nil)
+	}
+	return c.schoof(0, func(primesDone, primesTotal int, currentPrime int64, _, _ *big.Int) {
+		cb(primesDone, primesTotal, currentPrime)
+	})
+}
+
+// SchoofWithTrace is like SchoofWithProgress, but passes cb the Trace so far:
+// t in [0, m), where m is the product of the primes done, and t is the Trace
+// modulo m. Once m > 4√P, t determines the Trace.
+func (c *Curve) SchoofWithTrace(cb func(t, m *big.Int)) (*big.Int, error) {
+	if cb == nil {
+		return c.schoof(0, nil)
+	}
+	return c.schoof(0, func(_, _ int, _ int64, t, m *big.Int) {
+		cb(t, m)
+	})
+}
+
+// SchoofParallel is like Schoof, but runs at most maxWorkers TraceMod
+// computations at a time.
+func (c *Curve) SchoofParallel(maxWorkers int) (*big.Int, error) {
+	if maxWorkers < 1 {
+		maxWorkers = 1
+	}
+	return c.schoof(maxWorkers, nil)
+}
+
+// schoof runs at most maxWorkers TraceMod computations at a time, or all of
+// them at once if maxWorkers is 0.
+func (c *Curve) schoof(maxWorkers int, cb func(primesDone, primesTotal int, currentPrime int64, t, m *big.Int)) (*big.Int, error) {
 	q := c.P
-	l, M := big.NewInt(2), big.NewInt(1)
+	nextPrime := PrimeSeq(big.NewInt(2))
+	M := big.NewInt(1)
 	fsq := new(big.Int).Mul(new(big.Int).Sqrt(q), big.NewInt(4))
 
 	log.Printf("%s q= %v\n", c.poly(), q)
@@ -297,32 +398,41 @@ func (c *Curve) Schoof() (*big.Int, error) {
 	done := make(chan interface{})
 	defer close(done)
 
-	var ell []*big.Int
+	var sem chan struct{}
+	if maxWorkers > 0 {
+		sem = make(chan struct{}, maxWorkers)
+	}
+
 	var worker []<-chan interface{}
-	for M.Cmp(fsq) <= 0 {
-		ell = append(ell, l)
+	for l := nextPrime(); M.Cmp(fsq) <= 0; l = nextPrime() {
 		ec := &Curve{
 			P: c.P,
 			A: c.A,
 			B: c.B,
 		}
-		worker = append(worker, TraceMod(ec, l))
+		ell := l
+		worker = append(worker, Bounded(done, sem, func() <-chan interface{} {
+			return TraceMod(ec, ell)
+		}))
 		M.Mul(M, l)
-		l = NextPrime(l)
 	}
 
-	var tr []*big.Int
-	i := 0
+	var crt IncrementalCRT // chinese remainder theorem
+	primesDone := 0
 	for s := range ToTrace(done, FanIn(done, worker...)) {
 		if s.err != nil {
 			return nil, s.err
 		}
-		log.Println("Trace", s.tr, "mod", ell[i])
-		tr = append(tr, s.tr)
-		i++
+		log.Println("Trace", s.tr, "mod", s.ell)
+		crt.Add(s.tr, s.ell)
+		primesDone++
+		if cb != nil {
+			t, m := crt.Current()
+			cb(primesDone, len(worker), s.ell.Int64(), t, m)
+		}
 	}
 
-	t := CRT(tr, ell) // chinese remainder theorem
+	t, _ := crt.Current()
 	if t.Cmp(new(big.Int).Div(M, big.NewInt(2))) >= 0 {
 		t.Sub(t, M)
 	}
@@ -1,6 +1,9 @@
 package ecc
 
 import (
+	"fmt"
+	"io"
+	"log"
 	"math/big"
 	"testing"
 )
@@ -38,3 +41,344 @@ func TestSchoof(t *testing.T) {
 		}
 	}
 }
+
+func TestSchoofWithProgress(t *testing.T) {
+	c := &Curve{
+		P: big.NewInt(7919),
+		A: big.NewInt(1001),
+		B: big.NewInt(75),
+	}
+
+	var done []int
+	seen := make(map[int64]bool)
+	total := 0
+	got, err := c.SchoofWithProgress(func(primesDone, primesTotal int, currentPrime int64) {
+		done = append(done, primesDone)
+		total = primesTotal
+		if seen[currentPrime] {
+			t.Errorf("callback fired twice for %d", currentPrime)
+		}
+		seen[currentPrime] = true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(big.NewInt(7889)) != 0 {
+		t.Errorf("got: %d, want: 7889", got)
+	}
+
+	// 2·3·5·7·11 > 4√7919
+	if total != 5 || len(done) != total || len(seen) != total {
+		t.Errorf("callback fired %d times for %d primes", len(done), total)
+	}
+	for i, d := range done {
+		if d != i+1 {
+			t.Errorf("primesDone is not monotonic: %v", done)
+			break
+		}
+	}
+}
+
+func TestSchoofWithTrace(t *testing.T) {
+	c := &Curve{
+		P: big.NewInt(7919),
+		A: big.NewInt(1001),
+		B: big.NewInt(75),
+	}
+	// #E = 7889, so the Trace is 7919 + 1 - 7889 = 31
+	trace := big.NewInt(31)
+
+	prev := big.NewInt(1)
+	calls := 0
+	got, err := c.SchoofWithTrace(func(tr, m *big.Int) {
+		calls++
+		if new(big.Int).Mod(m, prev).Sign() != 0 || m.Cmp(prev) <= 0 {
+			t.Errorf("modulus %d does not extend %d", m, prev)
+		}
+		prev = m
+		if want := new(big.Int).Mod(trace, m); tr.Cmp(want) != 0 {
+			t.Errorf("Trace mod %d got: %d, want: %d", m, tr, want)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(big.NewInt(7889)) != 0 {
+		t.Errorf("got: %d, want: 7889", got)
+	}
+	// 2·3·5·7·11 > 4√7919
+	if calls != 5 || prev.Int64() != 2310 {
+		t.Errorf("callback fired %d times, final modulus %d, want: 5 times, 2310", calls, prev)
+	}
+}
+
+func TestScalarMul(t *testing.T) {
+	c := &Curve{
+		P: big.NewInt(97),
+		A: big.NewInt(46),
+		B: big.NewInt(74),
+	}
+	q, f := c.P, c.poly()
+
+	for _, ell := range []int64{3, 5, 7} {
+		qr := &Qring{c.DivPoly(ell).Monic(q), q}
+		id := NewEnd(qr, NewPolyFromInt(0, 1), NewPolyFromInt(1))
+		for n := int64(1); n < 3*ell; n++ {
+			got, err1 := ScalarMul(id, big.NewInt(n), c.A, f)
+			want, err2 := scalarMulBinary(id, big.NewInt(n), c.A, f)
+			if err1 != nil || err2 != nil {
+				t.Errorf("ell = %d, n = %d: got errors: %v, %v", ell, n, err1, err2)
+				continue
+			}
+			if !Eq(got, want) {
+				t.Errorf("ell = %d, n = %d: NAF and binary results differ", ell, n)
+			}
+		}
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	c := &Curve{
+		P: big.NewInt(97),
+		A: big.NewInt(46),
+		B: big.NewInt(74),
+	}
+	q, f := c.P, c.poly()
+	qr := &Qring{c.DivPoly(5).Monic(q), q}
+	id := Identity(qr)
+	pe := NewEnd(qr, NewPolyFromInt(0, 1), NewPolyFromInt(1))
+
+	if r, err := Add(id, pe, c.A, f); err != nil || !Eq(r, pe) {
+		t.Errorf("identity + P != P")
+	}
+	if r, err := Add(pe, id, c.A, f); err != nil || !Eq(r, pe) {
+		t.Errorf("P + identity != P")
+	}
+	if r, err := Add(pe, Neg(pe), c.A, f); err != nil || !r.IsIdentity() {
+		t.Errorf("P + (-P) != identity")
+	}
+	if r, err := Double(id, c.A, f); err != nil || !r.IsIdentity() {
+		t.Errorf("2·identity != identity")
+	}
+	if !Neg(id).IsIdentity() {
+		t.Errorf("-identity != identity")
+	}
+	if pe.IsIdentity() || Eq(pe, id) {
+		t.Errorf("P == identity")
+	}
+	if r, err := ScalarMul(pe, big.NewInt(5), c.A, f); err != nil || !r.IsIdentity() {
+		t.Errorf("5·P != identity in End(E[5])")
+	}
+	if r, err := ScalarMul(pe, big.NewInt(0), c.A, f); err != nil || !r.IsIdentity() {
+		t.Errorf("0·P != identity")
+	}
+}
+
+func TestQring(t *testing.T) {
+	// F_7[x]/(x^2 + 1) is the field of 49 elements
+	q := big.NewInt(7)
+	qr := NewQring(NewPolyFromInt(1, 0, 1), q)
+
+	cases := []struct {
+		p, q, prod Poly
+	}{
+		{NewPolyFromInt(1, 1), NewPolyFromInt(1, 1), NewPolyFromInt(0, 2)},
+		{NewPolyFromInt(0, 1), NewPolyFromInt(0, 1), NewPolyFromInt(6)},
+		{NewPolyFromInt(2, 3), NewPolyFromInt(4, 5), NewPolyFromInt(0, 1)},
+	}
+	for _, c := range cases {
+		if got := qr.Mul(c.p, c.q); !got.Equal(c.prod) {
+			t.Errorf("%v * %v: got: %v, want: %v", c.p, c.q, got, c.prod)
+		}
+	}
+
+	if got := qr.Reduce(NewPolyFromInt(0, 0, 0, 1)); !got.Equal(NewPolyFromInt(0, 6)) {
+		t.Errorf("x^3: got: %v, want: [6x]", got)
+	}
+
+	// x^48 = 1, x^4 = 1
+	if got := qr.Exp(NewPolyFromInt(1, 1), big.NewInt(48)); !got.IsOne() {
+		t.Errorf("(x+1)^48: got: %v, want: [1]", got)
+	}
+	if got := qr.Exp(NewPolyFromInt(0, 1), big.NewInt(4)); !got.IsOne() {
+		t.Errorf("x^4: got: %v, want: [1]", got)
+	}
+
+	for a := int64(0); a < 7; a++ {
+		for b := int64(0); b < 7; b++ {
+			p := NewPolyFromInt(int(a), int(b))
+			inv, err := qr.Inverse(p)
+			if a == 0 && b == 0 {
+				if err == nil {
+					t.Errorf("0 is invertible")
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%v: got error: %v", p, err)
+				continue
+			}
+			if got := qr.Mul(p, inv); !got.IsOne() {
+				t.Errorf("%v * %v = %v", p, inv, got)
+			}
+		}
+	}
+
+	// x^2 - 1 = (x - 1)(x + 1) is not irreducible
+	qr = NewQring(NewPolyFromInt(-1, 0, 1), q)
+	if _, err := qr.Inverse(NewPolyFromInt(1, 1)); err == nil {
+		t.Errorf("x + 1 is invertible mod x^2 - 1")
+	}
+}
+
+func TestSchoofParallel(t *testing.T) {
+	c := &Curve{
+		P: big.NewInt(7919),
+		A: big.NewInt(1001),
+		B: big.NewInt(75),
+	}
+	want, err := c.Schoof()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, maxWorkers := range []int{1, 2, 8} {
+		got, err := c.SchoofParallel(maxWorkers)
+		if err != nil {
+			t.Errorf("maxWorkers = %d: got error: %v", maxWorkers, err)
+			continue
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("maxWorkers = %d: got: %d, want: %d", maxWorkers, got, want)
+		}
+	}
+}
+
+func TestTraceModCachesFrobenius(t *testing.T) {
+	calls := 0
+	countingExp := func(qr *Qring, p Poly, e *big.Int) Poly {
+		calls++
+		return Exp(qr, p, e)
+	}
+
+	// both find a factor of the division polynomial and retry
+	cases := []struct {
+		c   *Curve
+		ell int64
+	}{
+		{&Curve{P: big.NewInt(19), A: big.NewInt(2), B: big.NewInt(1)}, 3},
+		{&Curve{P: big.NewInt(7919), A: big.NewInt(1001), B: big.NewInt(75)}, 5},
+	}
+	for _, c := range cases {
+		n := int64(1)
+		for x := big.NewInt(0); x.Cmp(c.c.P) < 0; x.Add(x, big.NewInt(1)) {
+			n += int64(1 + big.Jacobi(c.c.evaluatePolynomial(x), c.c.P))
+		}
+		want := ((c.c.P.Int64()+1-n)%c.ell + c.ell) % c.ell
+
+		calls = 0
+		tr := (<-traceMod(c.c, big.NewInt(c.ell), countingExp)).(*Trace)
+		if tr.err != nil {
+			t.Fatal(tr.err)
+		}
+		if got := new(big.Int).Mod(tr.tr, big.NewInt(c.ell)).Int64(); got != want {
+			t.Errorf("%s mod %d: got: %d, want: %d", c.c.poly(), c.ell, got, want)
+		}
+		if calls != 2 {
+			t.Errorf("%s mod %d: got: %d Exp calls, want: 2", c.c.poly(), c.ell, calls)
+		}
+	}
+}
+
+// schoofBenchCurves are y² = x³ + 3x + 5 over the least prime above 2^(bits-1),
+// with their orders: by OrderBruteForce for 16 and 20 bits, and checked
+// against random points for 32 bits. 64-bit primes take minutes per run, too
+// long for CI.
+var schoofBenchCurves = []struct {
+	bits int
+	p, n int64
+}{
+	{16, 32771, 33038},
+	{20, 524309, 523409},
+	{32, 2147483659, 2147544082},
+}
+
+// BenchmarkSchoof times the whole point count. Its hot paths, the products
+// and powers in F_q[x]/(ψ_ℓ), are timed on their own by BenchmarkQring, so
+// that faster polynomial arithmetic can be measured both ways.
+func BenchmarkSchoof(b *testing.B) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+
+	for _, bc := range schoofBenchCurves {
+		b.Run(fmt.Sprintf("%dbit", bc.bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c := &Curve{P: big.NewInt(bc.p), A: big.NewInt(3), B: big.NewInt(5)}
+				n, err := c.Schoof()
+				if err != nil || n.Int64() != bc.n {
+					b.Fatalf("got: %v, %v, want: %d", n, err, bc.n)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkQring(b *testing.B) {
+	bc := schoofBenchCurves[len(schoofBenchCurves)-1]
+	c := &Curve{P: big.NewInt(bc.p), A: big.NewInt(3), B: big.NewInt(5)}
+	q := c.P
+	for _, ell := range []int64{5, 7, 13} {
+		qr := NewQring(c.DivPoly(ell), q)
+		x := NewPolyFromInt(0, 1)
+		xq := qr.Exp(x, q)
+
+		b.Run(fmt.Sprintf("Mul/ℓ=%d", ell), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				qr.Mul(xq, xq)
+			}
+		})
+		b.Run(fmt.Sprintf("Exp/ℓ=%d", ell), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if got := qr.Exp(x, q); !got.Equal(xq) {
+					b.Fatalf("got: %v, want: %v", got, xq)
+				}
+			}
+		})
+	}
+}
+
+// scalarMulBinary computes the scalar multiple n*P in End(E[ell]) by double
+// and Add, as a check of ScalarMul
+func scalarMulBinary(pe *Endo, n *big.Int, A *big.Int, f Poly) (*Endo, error) {
+	var err error
+
+	if n == nil || n.Sign() == 0 {
+		return Identity(pe.qr), nil
+	}
+
+	re := pe
+	for i, b := range n.Bytes() {
+		j := 0
+		if i == 0 {
+			for j = 1; b&0x80 != 0x80; j++ {
+				b <<= 1
+			}
+			b <<= 1
+		}
+		for bitNum := j; bitNum < 8; bitNum++ {
+			if re, err = Double(re, A, f); err != nil {
+				return nil, err
+			}
+			if b&0x80 == 0x80 {
+				if re, err = Add(re, pe, A, f); err != nil {
+					return nil, err
+				}
+			}
+			b <<= 1
+		}
+	}
+
+	return re, nil
+}
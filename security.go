@@ -0,0 +1,148 @@
+package ecc
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// maxMOVDegree is the embedding degree up to which a curve is considered
+// open to the MOV and Frey-Rück attacks.
+const maxMOVDegree = 20
+
+// twistTrialBound bounds the small factors divided out of the twist order
+// before the rest is tested for primality.
+const twistTrialBound = 1 << 16
+
+// IsNonsingular reports whether the Discriminant is non-zero, so that the
+// curve has no cusps or nodes.
+func (c *Curve) IsNonsingular() bool {
+	return c.Discriminant().Sign() != 0
+}
+
+// Trace returns the trace of Frobenius t = P + 1 - #E.
+func (c *Curve) Trace() (*big.Int, error) {
+	n, err := c.groupOrder()
+	if err != nil {
+		return nil, err
+	}
+	t := new(big.Int).Add(c.P, big.NewInt(1))
+	return t.Sub(t, n), nil
+}
+
+// IsAnomalous reports whether #E = P, in which case the discrete logarithm
+// is solved in linear time by Smart's attack.
+func (c *Curve) IsAnomalous() (bool, error) {
+	t, err := c.Trace()
+	if err != nil {
+		return false, err
+	}
+	return t.Cmp(big.NewInt(1)) == 0, nil
+}
+
+// IsSupersingular reports whether the trace is zero modulo P, in which case
+// the embedding degree is at most 2.
+func (c *Curve) IsSupersingular() (bool, error) {
+	t, err := c.Trace()
+	if err != nil {
+		return false, err
+	}
+	return t.Mod(t, c.P).Sign() == 0, nil
+}
+
+// TwistOrder returns the number of points of the quadratic twist,
+// 2(P+1) - #E.
+func (c *Curve) TwistOrder() (*big.Int, error) {
+	n, err := c.groupOrder()
+	if err != nil {
+		return nil, err
+	}
+	t := new(big.Int).Add(c.P, big.NewInt(1))
+	t.Lsh(t, 1)
+	return t.Sub(t, n), nil
+}
+
+// EmbeddingDegree returns the least k such that N divides P^k - 1, or 0 if
+// k is greater than bound. #E takes the place of N if N is not set.
+func (c *Curve) EmbeddingDegree(bound int) (int, error) {
+	n := c.N
+	if n == nil {
+		var err error
+		if n, err = c.groupOrder(); err != nil {
+			return 0, err
+		}
+	}
+	if n.Cmp(big.NewInt(1)) <= 0 {
+		return 0, nil
+	}
+
+	p := new(big.Int).Mod(c.P, n)
+	pk := new(big.Int).Set(p)
+	for k := 1; k <= bound; k++ {
+		if pk.Cmp(big.NewInt(1)) == 0 {
+			return k, nil
+		}
+		pk.Mul(pk, p)
+		pk.Mod(pk, n)
+	}
+	return 0, nil
+}
+
+// isTwistSecure reports whether the twist order is a prime of at least half
+// the bits of P times factors below twistTrialBound, so that invalid-curve
+// points on the twist leak little about a secret scalar.
+func (c *Curve) isTwistSecure() (bool, error) {
+	n, err := c.TwistOrder()
+	if err != nil {
+		return false, err
+	}
+	r := new(big.Int)
+	for f := int64(2); f < twistTrialBound && n.Cmp(big.NewInt(f)) > 0; f++ {
+		for bf := big.NewInt(f); ; {
+			q, m := new(big.Int).QuoRem(n, bf, r)
+			if m.Sign() != 0 || q.Cmp(big.NewInt(1)) == 0 {
+				break
+			}
+			n = q
+		}
+	}
+	return n.ProbablyPrime(20) && 2*n.BitLen() >= c.P.BitLen(), nil
+}
+
+// IsSafeCurve runs a checklist against the curve and returns whether it
+// passes, along with the criteria it fails: N must be prime, and the curve
+// must be nonsingular, not anomalous, not supersingular, secure on its twist,
+// and of embedding degree greater than 20. Checks that need #E use N·H, or
+// Schoof if either is unset.
+func (c *Curve) IsSafeCurve() (bool, []string) {
+	var failed []string
+	fail := func(format string, a ...interface{}) {
+		failed = append(failed, fmt.Sprintf(format, a...))
+	}
+
+	if c.N == nil || !c.N.ProbablyPrime(20) {
+		fail("N is not prime")
+	}
+	if !c.IsNonsingular() {
+		fail("curve is singular")
+		return false, failed
+	}
+
+	if _, err := c.groupOrder(); err != nil {
+		fail("order unknown: %v", err)
+		return false, failed
+	}
+	if ok, _ := c.IsAnomalous(); ok {
+		fail("curve is anomalous")
+	}
+	if ok, _ := c.IsSupersingular(); ok {
+		fail("curve is supersingular")
+	}
+	if ok, _ := c.isTwistSecure(); !ok {
+		fail("twist is not secure")
+	}
+	if k, _ := c.EmbeddingDegree(maxMOVDegree); k != 0 {
+		fail("embedding degree %d is too small", k)
+	}
+
+	return len(failed) == 0, failed
+}
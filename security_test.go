@@ -0,0 +1,112 @@
+package ecc
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestIsSafeCurve(t *testing.T) {
+	for _, name := range []string{"S256", "P384"} {
+		curve := sampleCurves()[name]
+		if ok, failed := curve.IsSafeCurve(); !ok {
+			t.Errorf("%s: got: %v", name, failed)
+		}
+	}
+
+	cases := []struct {
+		c      *Curve
+		failed []string
+	}{
+		{
+			// y² = x³ + 1 over F_29 is supersingular, with #E = 30
+			&Curve{P: big.NewInt(29), A: big.NewInt(0), B: big.NewInt(1)},
+			[]string{"N is not prime", "curve is supersingular", "embedding degree 2 is too small"},
+		},
+		{
+			// anomalous: #E = P = 43
+			&Curve{P: big.NewInt(43), A: big.NewInt(1), B: big.NewInt(14), N: big.NewInt(43), H: big.NewInt(1)},
+			[]string{"curve is anomalous"},
+		},
+		{
+			&Curve{P: big.NewInt(43), A: big.NewInt(0), B: big.NewInt(0), N: big.NewInt(43), H: big.NewInt(1)},
+			[]string{"curve is singular"},
+		},
+	}
+	for _, c := range cases {
+		ok, failed := c.c.IsSafeCurve()
+		if ok || !reflect.DeepEqual(failed, c.failed) {
+			t.Errorf("%s over F_%d: got: %v, %q, want: false, %q", c.c.poly(), c.c.P, ok, failed, c.failed)
+		}
+	}
+}
+
+func TestSetOrder(t *testing.T) {
+	// y² = x³ + 1 over F_29 is supersingular, with #E = 30
+	c := &Curve{P: big.NewInt(29), A: big.NewInt(0), B: big.NewInt(1)}
+	c.SetOrder(big.NewInt(30))
+	if tr, err := c.Trace(); err != nil || tr.Sign() != 0 {
+		t.Errorf("got: %d, %v, want: 0", tr, err)
+	}
+	if ok, err := c.IsSupersingular(); err != nil || !ok {
+		t.Errorf("got: %v, %v, want: true", ok, err)
+	}
+	if n, err := c.TwistOrder(); err != nil || n.Int64() != 30 {
+		t.Errorf("got: %d, %v, want: 30", n, err)
+	}
+
+	// far too large for Schoof, so the order must be the one set
+	curve := sampleCurves()["P384"]
+	n := new(big.Int).Mul(curve.N, curve.H)
+	curve.N, curve.H = nil, nil
+	curve.SetOrder(n)
+	want := new(big.Int).Add(curve.P, big.NewInt(1))
+	want.Sub(want, n)
+	if tr, err := curve.Trace(); err != nil || tr.Cmp(want) != 0 {
+		t.Errorf("got: %d, %v, want: %d", tr, err, want)
+	}
+	if ok, err := curve.IsAnomalous(); err != nil || ok {
+		t.Errorf("got: %v, %v, want: false", ok, err)
+	}
+	if k, err := curve.EmbeddingDegree(maxMOVDegree); err != nil || k != 0 {
+		t.Errorf("got: %d, %v, want: 0", k, err)
+	}
+}
+
+func TestTwistOrder(t *testing.T) {
+	testAllCurves(t, func(t *testing.T, curve *Curve) {
+		n, err := curve.TwistOrder()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// #E + #E' = 2(P+1)
+		sum := new(big.Int).Add(n, new(big.Int).Mul(curve.N, curve.H))
+		want := new(big.Int).Lsh(new(big.Int).Add(curve.P, big.NewInt(1)), 1)
+		if sum.Cmp(want) != 0 {
+			t.Errorf("got: %d, want: %d", sum, want)
+		}
+	})
+}
+
+func TestEmbeddingDegree(t *testing.T) {
+	cases := []struct {
+		c     *Curve
+		bound int
+		ans   int
+	}{
+		// supersingular, #E = 30 divides 29² - 1
+		{&Curve{P: big.NewInt(29), A: big.NewInt(0), B: big.NewInt(1)}, 20, 2},
+		// 37 divides 29^k - 1 first for k = 12
+		{sampleCurves()["TOY"], 11, 0},
+		{sampleCurves()["TOY"], 20, 12},
+	}
+	for _, c := range cases {
+		k, err := c.c.EmbeddingDegree(c.bound)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != c.ans {
+			t.Errorf("%s over F_%d: got: %d, want: %d", c.c.poly(), c.c.P, k, c.ans)
+		}
+	}
+}
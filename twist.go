@@ -0,0 +1,49 @@
+package ecc
+
+import "math/big"
+
+// nonResidue returns the least quadratic non-residue modulo the odd prime p.
+func nonResidue(p *big.Int) *big.Int {
+	d := big.NewInt(2)
+	for big.Jacobi(d, p) != -1 {
+		d.Add(d, big.NewInt(1))
+	}
+	return d
+}
+
+// Twist returns the quadratic twist y² = x³ + A·d²·x + B·d³ of the curve,
+// where d is the least quadratic non-residue. When the order of the curve is
+// known from N and H, the order of the twist is 2(P+1) - N·H; if it is prime,
+// it becomes N of the twist, with a base Point of the least x-coordinate.
+func (c *Curve) Twist() *Curve {
+	P := c.P
+	d := nonResidue(P)
+	d2 := new(big.Int).Mul(d, d)
+	d3 := new(big.Int).Mul(d2, d)
+
+	t := &Curve{
+		P:       new(big.Int).Set(P),
+		A:       d2.Mul(d2, c.A).Mod(d2, P),
+		B:       d3.Mul(d3, c.B).Mod(d3, P),
+		BitSize: c.BitSize,
+	}
+	if c.N == nil || c.H == nil {
+		return t
+	}
+
+	n := new(big.Int).Add(P, big.NewInt(1))
+	n.Lsh(n, 1)
+	n.Sub(n, new(big.Int).Mul(c.N, c.H))
+	if !n.ProbablyPrime(20) {
+		return t
+	}
+	t.N, t.H = n, big.NewInt(1)
+	for x := big.NewInt(0); x.Cmp(P) < 0; x.Add(x, big.NewInt(1)) {
+		if y := modSqrt(t.evaluatePolynomial(x), P); y != nil && y.Sign() != 0 {
+			t.Gx, t.Gy = x, y
+			break
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,72 @@
+package ecc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestTwist(t *testing.T) {
+	for _, name := range []string{"TOY", "SMALL"} {
+		t.Run(name, func(t *testing.T) {
+			testTwist(t, sampleCurves()[name])
+		})
+	}
+}
+
+func testTwist(t *testing.T, c *Curve) {
+	tw := c.Twist()
+	P := c.P
+	d := nonResidue(P)
+
+	// (x, y) ↦ (d·x, d·√d·y) maps E to E^d over F_p(√d), so for each x either
+	// f(x) or f^d(d·x) = d³·f(x) is a square in F_p, but not both
+	count := func(c *Curve) int64 {
+		n := int64(1)
+		for x := big.NewInt(0); x.Cmp(c.P) < 0; x.Add(x, big.NewInt(1)) {
+			n += int64(1 + big.Jacobi(c.evaluatePolynomial(x), c.P))
+		}
+		return n
+	}
+	for x := big.NewInt(0); x.Cmp(P) < 0; x.Add(x, big.NewInt(1)) {
+		dx := new(big.Int).Mul(d, x)
+		dx.Mod(dx, P)
+		j1 := big.Jacobi(c.evaluatePolynomial(x), P)
+		j2 := big.Jacobi(tw.evaluatePolynomial(dx), P)
+		if j1 != -j2 {
+			t.Errorf("x = %d: (f(x)/p) = %d, (f^d(dx)/p) = %d", x, j1, j2)
+		}
+	}
+
+	n := new(big.Int).Add(P, big.NewInt(1))
+	n.Lsh(n, 1)
+	if got, want := count(c)+count(tw), n.Int64(); got != want {
+		t.Errorf("#E + #E^d = %d, want: %d", got, want)
+	}
+
+	// #E^d = 23 is prime for TOY, 221 = 13·17 is not for SMALL
+	if tw.N != nil {
+		if tw.N.Int64() != count(tw) {
+			t.Errorf("got N = %d, want %d", tw.N, count(tw))
+		}
+		if !tw.IsOnCurve(tw.Gx, tw.Gy) {
+			t.Errorf("base Point of the twist is not on the twist")
+		}
+		if x, y := tw.ScalarBaseMult(tw.N); x.Sign() != 0 || y.Sign() != 0 {
+			t.Errorf("N·G != ∞ on the twist")
+		}
+	}
+
+	// Twist().Twist() is E^(d²), isomorphic to E by (x, y) ↦ (d²x, d³y)
+	tt := tw.Twist()
+	j1, _ := c.JInvariant()
+	j2, _ := tt.JInvariant()
+	if j1.Cmp(j2) != 0 || count(c) != count(tt) {
+		t.Errorf("Twist().Twist() is not isomorphic to E")
+	}
+	d2 := new(big.Int).Mul(d, d)
+	d3 := new(big.Int).Mul(d2, d)
+	x, y := new(big.Int).Mul(d2, c.Gx), new(big.Int).Mul(d3, c.Gy)
+	if !tt.IsOnCurve(x.Mod(x, P), y.Mod(y, P)) {
+		t.Errorf("(d²Gx, d³Gy) is not on Twist().Twist()")
+	}
+}
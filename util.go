@@ -47,6 +47,36 @@ func NextPrime(n *big.Int) *big.Int {
 	return p
 }
 
+// PrimeSeq returns a function yielding the successive primes from start,
+// inclusive.
+func PrimeSeq(start *big.Int) func() *big.Int {
+	var p *big.Int
+	return func() *big.Int {
+		if p == nil {
+			p = new(big.Int).Sub(start, big.NewInt(1))
+		}
+		p = NextPrime(p)
+		return new(big.Int).Set(p)
+	}
+}
+
+// naf returns the non-adjacent form of k >= 0, least significant digit
+// first. Each digit is -1, 0 or 1, and no two adjacent digits are non-zero.
+func naf(k *big.Int) []int8 {
+	var digits []int8
+	n := new(big.Int).Set(k)
+	for n.Sign() > 0 {
+		var d int8
+		if n.Bit(0) == 1 {
+			d = 2 - int8(n.Bit(1)<<1|n.Bit(0)) // 2 - (n mod 4)
+			n.Sub(n, big.NewInt(int64(d)))
+		}
+		digits = append(digits, d)
+		n.Rsh(n, 1)
+	}
+	return digits
+}
+
 // CRT Chinese remainder theorem
 func CRT(a, n []*big.Int) *big.Int {
 	if a == nil || n == nil {
@@ -68,12 +98,158 @@ func CRT(a, n []*big.Int) *big.Int {
 	return c.Mod(&c, p)
 }
 
+// IncrementalCRT combines residues modulo pairwise coprime moduli as they
+// arrive, keeping the solution modulo the product of the moduli so far. The
+// zero value is ready to use and stands for 0 modulo 1.
+type IncrementalCRT struct {
+	value, modulus *big.Int
+}
+
+// Add combines x = residue (mod modulus) into the solution. It panics if
+// modulus is not coprime to the moduli added before.
+func (ic *IncrementalCRT) Add(residue, modulus *big.Int) {
+	if ic.modulus == nil {
+		ic.value, ic.modulus = new(big.Int), big.NewInt(1)
+	}
+	inv := new(big.Int).ModInverse(new(big.Int).Mod(ic.modulus, modulus), modulus)
+	if inv == nil && modulus.Cmp(big.NewInt(1)) != 0 {
+		panic("ecc: CRT moduli are not coprime")
+	}
+
+	// value + modulus·((residue - value)·modulus⁻¹ mod m)
+	d := new(big.Int).Sub(residue, ic.value)
+	if inv != nil {
+		d.Mul(d, inv)
+	}
+	d.Mod(d, modulus)
+	ic.value.Add(ic.value, d.Mul(d, ic.modulus))
+	ic.modulus.Mul(ic.modulus, modulus)
+}
+
+// Current returns the solution so far, in [0, modulus).
+func (ic *IncrementalCRT) Current() (value, modulus *big.Int) {
+	if ic.modulus == nil {
+		return new(big.Int), big.NewInt(1)
+	}
+	return new(big.Int).Set(ic.value), new(big.Int).Set(ic.modulus)
+}
+
 // FermatInverse calculates the inverse of k in GF(P) using Fermat's method
-// (exponentiation modulo P - 2, per Euler's theorem).
+// (exponentiation modulo P - 2, per Euler's theorem). N must be prime.
 func FermatInverse(k, N *big.Int) *big.Int {
 	return new(big.Int).Exp(k, new(big.Int).Sub(N, big.NewInt(2)), N)
 }
 
+// BatchModInverse returns the inverses of xs modulo m with a single
+// ModInverse, using Montgomery's trick. Entries not invertible modulo m are
+// nil; only if there are any are the xs checked one by one to find them.
+func BatchModInverse(xs []*big.Int, m *big.Int) []*big.Int {
+	inv := make([]*big.Int, len(xs))
+
+	// prefix[i] is the product of xs[:i]
+	prefix := make([]*big.Int, len(xs)+1)
+	prefix[0] = big.NewInt(1)
+	for i, x := range xs {
+		prefix[i+1] = new(big.Int).Mul(prefix[i], x)
+		prefix[i+1].Mod(prefix[i+1], m)
+	}
+
+	t := new(big.Int).ModInverse(prefix[len(xs)], m)
+	if t == nil {
+		return batchModInverseSlow(xs, m)
+	}
+	for i := len(xs) - 1; i >= 0; i-- {
+		inv[i] = new(big.Int).Mul(t, prefix[i])
+		inv[i].Mod(inv[i], m)
+		t.Mul(t, xs[i])
+		t.Mod(t, m)
+	}
+
+	return inv
+}
+
+// batchModInverseSlow is BatchModInverse when some of xs are not invertible:
+// it finds them with a GCD each and batches the rest.
+func batchModInverseSlow(xs []*big.Int, m *big.Int) []*big.Int {
+	inv := make([]*big.Int, len(xs))
+	var idx []int
+	var good []*big.Int
+	g, one := new(big.Int), big.NewInt(1)
+	for i, x := range xs {
+		if g.GCD(nil, nil, new(big.Int).Mod(x, m), m).Cmp(one) == 0 {
+			idx = append(idx, i)
+			good = append(good, x)
+		}
+	}
+	for j, w := range BatchModInverse(good, m) {
+		inv[idx[j]] = w
+	}
+	return inv
+}
+
+// Legendre returns the Legendre symbol (a/p) for an odd prime p: 1 if a is a
+// non-zero square modulo p, -1 if it is not a square, and 0 if p divides a.
+func Legendre(a, p *big.Int) int {
+	return big.Jacobi(new(big.Int).Mod(a, p), p)
+}
+
+// modSqrt returns a square root of a modulo the odd prime p, or nil if a is
+// not a quadratic residue. It takes the (p+1)/4 shortcut when p = 3 mod 4 and
+// falls back to Tonelli-Shanks otherwise.
+func modSqrt(a, p *big.Int) *big.Int {
+	a = new(big.Int).Mod(a, p)
+	if a.Sign() == 0 {
+		return a
+	}
+	if big.Jacobi(a, p) != 1 {
+		return nil
+	}
+
+	one := big.NewInt(1)
+	if p.Bit(1) == 1 {
+		e := new(big.Int).Add(p, one)
+		e.Rsh(e, 2)
+		return a.Exp(a, e, p)
+	}
+
+	// p - 1 = q * 2^s with q odd
+	q := new(big.Int).Sub(p, one)
+	s := 0
+	for q.Bit(0) == 0 {
+		q.Rsh(q, 1)
+		s++
+	}
+
+	// z is a quadratic non-residue
+	z := big.NewInt(2)
+	for big.Jacobi(z, p) != -1 {
+		z.Add(z, one)
+	}
+
+	c := new(big.Int).Exp(z, q, p)
+	t := new(big.Int).Exp(a, q, p)
+	e := new(big.Int).Add(q, one)
+	r := new(big.Int).Exp(a, e.Rsh(e, 1), p)
+	b := new(big.Int)
+	for t.Cmp(one) != 0 {
+		// least i with t^(2^i) = 1
+		i := 0
+		for b.Set(t); b.Cmp(one) != 0; i++ {
+			b.Mul(b, b).Mod(b, p)
+		}
+		b.Set(c)
+		for j := 0; j < s-i-1; j++ {
+			b.Mul(b, b).Mod(b, p)
+		}
+		s = i
+		c.Mul(b, b).Mod(c, p)
+		t.Mul(t, c).Mod(t, p)
+		r.Mul(r, b).Mod(r, p)
+	}
+
+	return r
+}
+
 func FanIn(done <-chan interface{}, channels ...<-chan interface{}) <-chan interface{} {
 	var wg sync.WaitGroup
 	multiplexedStream := make(chan interface{})
@@ -102,6 +278,35 @@ func FanIn(done <-chan interface{}, channels ...<-chan interface{}) <-chan inter
 	return multiplexedStream
 }
 
+// Bounded starts the stream once a slot of sem is free, and frees the slot
+// when the stream is closed. If sem is nil, the stream starts at once.
+func Bounded(done <-chan interface{}, sem chan struct{}, stream func() <-chan interface{}) <-chan interface{} {
+	if sem == nil {
+		return stream()
+	}
+
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+
+		select {
+		case <-done:
+			return
+		case sem <- struct{}{}:
+		}
+		defer func() { <-sem }()
+
+		for v := range stream() {
+			select {
+			case <-done:
+				return
+			case ch <- v:
+			}
+		}
+	}()
+	return ch
+}
+
 func ToTrace(done <-chan interface{}, stream <-chan interface{}) <-chan *Trace {
 	ch := make(chan *Trace)
 	go func() {
@@ -116,3 +321,34 @@ func ToTrace(done <-chan interface{}, stream <-chan interface{}) <-chan *Trace {
 	}()
 	return ch
 }
+
+// Cornacchia solves x² + d·y² = p for an odd prime p and 0 < d < p. ok is
+// false if there is no solution.
+func Cornacchia(d, p *big.Int) (x, y *big.Int, ok bool) {
+	if d.Sign() <= 0 || d.Cmp(p) >= 0 {
+		return nil, nil, false
+	}
+	r := modSqrt(new(big.Int).Sub(p, d), p)
+	if r == nil {
+		return nil, nil, false
+	}
+	if half := new(big.Int).Rsh(p, 1); r.Cmp(half) <= 0 {
+		r.Sub(p, r)
+	}
+
+	a, b := new(big.Int).Set(p), r
+	bound := new(big.Int).Sqrt(p)
+	for b.Cmp(bound) > 0 {
+		a, b = b, new(big.Int).Mod(a, b)
+	}
+
+	s, rem := new(big.Int).QuoRem(new(big.Int).Sub(p, new(big.Int).Mul(b, b)), d, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, nil, false
+	}
+	y = new(big.Int).Sqrt(s)
+	if new(big.Int).Mul(y, y).Cmp(s) != 0 {
+		return nil, nil, false
+	}
+	return b, y, true
+}
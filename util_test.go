@@ -1,8 +1,11 @@
 package ecc
 
 import (
+	"crypto/rand"
 	"math/big"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNextPrime(t *testing.T) {
@@ -34,3 +37,255 @@ func TestNextPrime(t *testing.T) {
 		}
 	}
 }
+
+func TestModSqrt(t *testing.T) {
+	// 7919 = 3 mod 4, 97 = 1 mod 8, 29 = 5 mod 8
+	for _, p := range []*big.Int{big.NewInt(7919), big.NewInt(97), big.NewInt(29)} {
+		for a := big.NewInt(0); a.Cmp(p) < 0; a.Add(a, big.NewInt(1)) {
+			want := new(big.Int).ModSqrt(a, p)
+			got := modSqrt(a, p)
+			if want == nil {
+				if got != nil {
+					t.Errorf("sqrt(%d) mod %d: got: %d, want: nil", a, p, got)
+				}
+				continue
+			}
+			if got == nil {
+				t.Errorf("sqrt(%d) mod %d: got: nil", a, p)
+				continue
+			}
+			got.Mul(got, got).Mod(got, p)
+			if got.Cmp(a) != 0 {
+				t.Errorf("sqrt(%d)^2 mod %d = %d", a, p, got)
+			}
+		}
+	}
+}
+
+func TestPrimeSeq(t *testing.T) {
+	cases := []struct {
+		start int64
+		want  []int64
+	}{
+		{0, []int64{2, 3, 5, 7, 11, 13}},
+		{2, []int64{2, 3, 5, 7, 11, 13}},
+		{14, []int64{17, 19, 23, 29, 31}},
+		{97, []int64{97, 101, 103, 107}},
+	}
+	for _, c := range cases {
+		next := PrimeSeq(big.NewInt(c.start))
+		for _, want := range c.want {
+			if got := next(); got.Int64() != want {
+				t.Errorf("PrimeSeq(%d): got: %v, want: %v", c.start, got, want)
+			}
+		}
+	}
+}
+
+func TestBatchModInverse(t *testing.T) {
+	cases := []struct {
+		xs []int64
+		m  int64
+	}{
+		{[]int64{}, 7},
+		{[]int64{1, 2, 3, 4, 5, 6}, 7},
+		{[]int64{3, 0, 5, 7919, -2, 14}, 7919},
+		{[]int64{2, 3, 4, 5, 6, 7, 8, 9}, 12},
+	}
+	for _, c := range cases {
+		xs := make([]*big.Int, len(c.xs))
+		for i, x := range c.xs {
+			xs[i] = big.NewInt(x)
+		}
+		m := big.NewInt(c.m)
+		got := BatchModInverse(xs, m)
+		if len(got) != len(xs) {
+			t.Fatalf("got %d inverses, want %d", len(got), len(xs))
+		}
+		for i, x := range xs {
+			want := new(big.Int).ModInverse(new(big.Int).Mod(x, m), m)
+			if want == nil {
+				if got[i] != nil {
+					t.Errorf("%d^-1 mod %d: got: %d, want: nil", x, m, got[i])
+				}
+				continue
+			}
+			if got[i] == nil || got[i].Cmp(want) != 0 {
+				t.Errorf("%d^-1 mod %d: got: %d, want: %d", x, m, got[i], want)
+			}
+		}
+	}
+}
+
+func BenchmarkBatchModInverse(b *testing.B) {
+	m := sampleCurves()["S256"].P
+	xs := make([]*big.Int, 256)
+	for i := range xs {
+		xs[i], _ = rand.Int(rand.Reader, m)
+	}
+	b.Run("Batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			BatchModInverse(xs, m)
+		}
+	})
+	b.Run("ModInverse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, x := range xs {
+				new(big.Int).ModInverse(x, m)
+			}
+		}
+	})
+}
+
+func TestNAF(t *testing.T) {
+	for k := int64(0); k < 1000; k++ {
+		digits := naf(big.NewInt(k))
+		v := new(big.Int)
+		for i := len(digits) - 1; i >= 0; i-- {
+			v.Lsh(v, 1)
+			v.Add(v, big.NewInt(int64(digits[i])))
+			if i > 0 && digits[i] != 0 && digits[i-1] != 0 {
+				t.Errorf("naf(%d) = %v has adjacent non-zero digits", k, digits)
+			}
+		}
+		if v.Int64() != k {
+			t.Errorf("naf(%d) = %v evaluates to %d", k, digits, v)
+		}
+	}
+}
+
+func TestBounded(t *testing.T) {
+	done := make(chan interface{})
+	defer close(done)
+
+	sem := make(chan struct{}, 2)
+	running, peak := 0, 0
+	var mu sync.Mutex
+
+	var streams []<-chan interface{}
+	for i := 0; i < 8; i++ {
+		i := i
+		streams = append(streams, Bounded(done, sem, func() <-chan interface{} {
+			ch := make(chan interface{})
+			go func() {
+				defer close(ch)
+				mu.Lock()
+				running++
+				if running > peak {
+					peak = running
+				}
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				ch <- i
+			}()
+			return ch
+		}))
+	}
+
+	n := 0
+	for range FanIn(done, streams...) {
+		n++
+	}
+	if n != 8 {
+		t.Errorf("got %d values, want 8", n)
+	}
+	if peak > 2 {
+		t.Errorf("%d streams ran at once, want at most 2", peak)
+	}
+}
+
+func TestLegendre(t *testing.T) {
+	p := big.NewInt(23)
+	squares := make(map[int64]bool)
+	for x := int64(1); x < 23; x++ {
+		squares[x*x%23] = true
+	}
+	for a := int64(-30); a <= 30; a++ {
+		want := -1
+		if m := (a%23 + 23) % 23; m == 0 {
+			want = 0
+		} else if squares[m] {
+			want = 1
+		}
+		if got := Legendre(big.NewInt(a), p); got != want {
+			t.Errorf("(%d/23) got: %d, want: %d", a, got, want)
+		}
+	}
+}
+
+func TestCornacchia(t *testing.T) {
+	// x² + y² = p is solvable iff p = 1 mod 4, x² + 3y² = p iff p = 1 mod 3
+	mods := map[int64]int64{1: 4, 3: 3}
+	for _, p := range []int64{13, 37, 61, 97, 1009, 1000003} {
+		for d, m := range mods {
+			x, y, ok := Cornacchia(big.NewInt(d), big.NewInt(p))
+			if p%m != 1 {
+				if ok {
+					t.Errorf("x² + %d·y² = %d: got: (%d, %d), want: no solution", d, p, x, y)
+				}
+				continue
+			}
+			if !ok {
+				t.Errorf("x² + %d·y² = %d: no solution found", d, p)
+				continue
+			}
+			got := new(big.Int).Mul(x, x)
+			got.Add(got, new(big.Int).Mul(big.NewInt(d), new(big.Int).Mul(y, y)))
+			if got.Int64() != p {
+				t.Errorf("%d² + %d·%d² = %d, want: %d", x, d, y, got, p)
+			}
+		}
+	}
+
+	cases := []struct {
+		d, p int64
+		ok   bool
+	}{
+		{2, 11, true},  // 3² + 2·1²
+		{2, 7, false},  // 7 = 7 mod 8
+		{5, 29, true},  // 3² + 5·2²
+		{0, 13, false}, // d out of range
+		{13, 13, false},
+	}
+	for _, c := range cases {
+		x, y, ok := Cornacchia(big.NewInt(c.d), big.NewInt(c.p))
+		if ok != c.ok {
+			t.Errorf("x² + %d·y² = %d: got: %v, want: %v", c.d, c.p, ok, c.ok)
+			continue
+		}
+		if ok && x.Int64()*x.Int64()+c.d*y.Int64()*y.Int64() != c.p {
+			t.Errorf("%d² + %d·%d² != %d", x, c.d, y, c.p)
+		}
+	}
+}
+
+func TestIncrementalCRT(t *testing.T) {
+	a := []*big.Int{big.NewInt(1), big.NewInt(0), big.NewInt(-1), big.NewInt(3), big.NewInt(12)}
+	n := []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(5), big.NewInt(7), big.NewInt(13)}
+
+	var ic IncrementalCRT
+	if v, m := ic.Current(); v.Sign() != 0 || m.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("empty: got: %d mod %d, want: 0 mod 1", v, m)
+	}
+	M := big.NewInt(1)
+	for i := range a {
+		ic.Add(a[i], n[i])
+		M.Mul(M, n[i])
+		v, m := ic.Current()
+		if want := CRT(a[:i+1], n[:i+1]); v.Cmp(want) != 0 || m.Cmp(M) != 0 {
+			t.Errorf("after %d residues: got: %d mod %d, want: %d mod %d", i+1, v, m, want, M)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic on a modulus that is not coprime")
+		}
+	}()
+	ic.Add(big.NewInt(1), big.NewInt(4))
+}
@@ -0,0 +1,130 @@
+package ecc
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Vector is an ECDSA verification test case in the Wycheproof JSON format,
+// with the fields of its test group attached.
+type Vector struct {
+	TcID    int      `json:"tcId"`
+	Comment string   `json:"comment"`
+	Flags   []string `json:"flags"`
+	Msg     string   `json:"msg"`
+	Sig     string   `json:"sig"`
+	Result  string   `json:"result"` // valid, invalid or acceptable
+
+	Curve     string `json:"-"`
+	PublicKey string `json:"-"` // uncompressed, hex
+	SHA       string `json:"-"`
+}
+
+// loadWycheproof reads the vectors of an ecdsa_verify_schema.json file.
+func loadWycheproof(path string) ([]Vector, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		TestGroups []struct {
+			PublicKey struct {
+				Curve        string `json:"curve"`
+				Uncompressed string `json:"uncompressed"`
+			} `json:"publicKey"`
+			SHA   string   `json:"sha"`
+			Tests []Vector `json:"tests"`
+		} `json:"testGroups"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+
+	var vs []Vector
+	for _, g := range file.TestGroups {
+		for _, v := range g.Tests {
+			v.Curve = g.PublicKey.Curve
+			v.PublicKey = g.PublicKey.Uncompressed
+			v.SHA = g.SHA
+			vs = append(vs, v)
+		}
+	}
+	return vs, nil
+}
+
+// TestWycheproofECDSA runs the upstream Wycheproof ECDSA verification
+// vectors in testdata/wycheproof; see the README there for their source.
+func TestWycheproofECDSA(t *testing.T) {
+	curves := map[string]*Curve{
+		"secp256r1": p256(),
+		"secp384r1": sampleCurves()["P384"],
+		"secp256k1": sampleCurves()["S256"],
+	}
+	hashes := map[string]func() hash.Hash{
+		"SHA-256": sha256.New,
+		"SHA-384": sha512.New384,
+		"SHA-512": sha512.New,
+	}
+
+	files, err := filepath.Glob(filepath.Join("testdata", "wycheproof", "ecdsa_*_test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no vectors found")
+	}
+	for _, file := range files {
+		vs, err := loadWycheproof(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checked := 0
+		for _, v := range vs {
+			curve, ok := curves[v.Curve]
+			h, hok := hashes[v.SHA]
+			if !ok || !hok {
+				continue
+			}
+			checked++
+			got := verifyVector(t, curve, h, v)
+			if v.Result == "valid" && !got || v.Result == "invalid" && got {
+				t.Errorf("%s #%d (%s): got: %v, want: %s", filepath.Base(file), v.TcID, v.Comment, got, v.Result)
+			}
+		}
+		if checked == 0 {
+			t.Errorf("%s: no vectors for a known curve and hash", filepath.Base(file))
+		}
+	}
+}
+
+func verifyVector(t *testing.T, curve *Curve, h func() hash.Hash, v Vector) bool {
+	pub, err := hex.DecodeString(v.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := curve.Unmarshal(pub)
+	if x == nil {
+		return false
+	}
+	msg, err := hex.DecodeString(v.Msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := hex.DecodeString(v.Sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s, err := DecodeSignatureDER(sig)
+	if err != nil {
+		return false
+	}
+	d := h()
+	d.Write(msg)
+	return curve.Verify(x, y, d.Sum(nil), r, s)
+}